@@ -0,0 +1,181 @@
+// Package catalog provides localizations loaded from translation files at
+// runtime, reloading them when the files change, to be used by a generated
+// Localizer in place of its generated localizations.
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/m1/go-localize/loader"
+)
+
+// layer is an input folder of translation files.
+type layer struct {
+	fsys fs.FS
+	root string
+}
+
+// Catalog is a set of localizations loaded from folders of translation files,
+// using the same layout and formats as the generator. It is safe for
+// concurrent use, reloads being swapped in atomically.
+type Catalog struct {
+	layers        []layer
+	loader        loader.Options
+	localizations atomic.Value // map[string]string
+	fingerprint   string
+	mu            sync.Mutex
+}
+
+// Options configures how a catalog loads its translation files.
+type Options struct {
+	// BaseLocale is the locale of the translation files without one, e.g. in
+	// an Android values folder or messages.properties, the same as the
+	// generator's -base-locale.
+	BaseLocale string
+}
+
+// New loads a catalog from the dirs on the OS file system, later dirs
+// overriding earlier ones key-by-key.
+func New(dirs ...string) (*Catalog, error) {
+	return Options{}.New(dirs...)
+}
+
+// New loads a catalog from the dirs on the OS file system, like New, keying
+// the files that don't have a locale by the base locale.
+func (o Options) New(dirs ...string) (*Catalog, error) {
+	c := &Catalog{loader: loader.Options{BaseLocale: o.BaseLocale}}
+	for _, dir := range dirs {
+		c.layers = append(c.layers, layer{fsys: os.DirFS(dir), root: "."})
+	}
+	return c, c.Reload()
+}
+
+// NewFS loads a catalog from the roots in fsys, later roots overriding
+// earlier ones key-by-key.
+func NewFS(fsys fs.FS, roots ...string) (*Catalog, error) {
+	return Options{}.NewFS(fsys, roots...)
+}
+
+// NewFS loads a catalog from the roots in fsys, like NewFS, keying the files
+// that don't have a locale by the base locale.
+func (o Options) NewFS(fsys fs.FS, roots ...string) (*Catalog, error) {
+	c := &Catalog{loader: loader.Options{BaseLocale: o.BaseLocale}}
+	for _, root := range roots {
+		c.layers = append(c.layers, layer{fsys: fsys, root: path.Clean(root)})
+	}
+	return c, c.Reload()
+}
+
+// Lookup returns the localization of key, including the locale, e.g.
+// en.messages.hello.
+func (c *Catalog) Lookup(key string) (string, bool) {
+	localizations, _ := c.localizations.Load().(map[string]string)
+	str, ok := localizations[key]
+	return str, ok
+}
+
+// Reload loads the translation files again. If loading fails the catalog
+// keeps its current localizations.
+func (c *Catalog) Reload() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fingerprint, err := c.stat()
+	if err != nil {
+		return err
+	}
+	return c.reload(fingerprint)
+}
+
+func (c *Catalog) reload(fingerprint string) error {
+	localizations := map[string]string{}
+	for _, l := range c.layers {
+		layerLocalizations, err := c.loader.Load(l.fsys, l.root)
+		if err != nil {
+			return err
+		}
+		for key, value := range layerLocalizations {
+			localizations[key] = value
+		}
+	}
+
+	c.localizations.Store(localizations)
+	c.fingerprint = fingerprint
+	return nil
+}
+
+// Watch reloads the catalog when its translation files change, checking
+// every interval, or when the process receives SIGHUP, until ctx is done.
+// Reload errors are passed to onError, if set, and the catalog keeps its
+// current localizations.
+func (c *Catalog) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			err = c.Reload()
+		case <-ticker.C:
+			err = c.reloadChanged()
+		}
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// reloadChanged reloads the catalog if its translation files changed since
+// the last reload.
+func (c *Catalog) reloadChanged() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fingerprint, err := c.stat()
+	if err != nil {
+		return err
+	}
+	if fingerprint == c.fingerprint {
+		return nil
+	}
+	return c.reload(fingerprint)
+}
+
+// stat returns a fingerprint of the translation files, changing when any of
+// them are added, removed or modified.
+func (c *Catalog) stat() (string, error) {
+	var files []string
+	for i, l := range c.layers {
+		paths, err := loader.Files(l.fsys, l.root)
+		if err != nil {
+			return "", err
+		}
+		for _, p := range paths {
+			info, err := fs.Stat(l.fsys, p)
+			if err != nil {
+				return "", err
+			}
+			files = append(files, fmt.Sprintf("%d:%v:%d:%d", i, p, info.Size(), info.ModTime().UnixNano()))
+		}
+	}
+	sort.Strings(files)
+	return strings.Join(files, "\n"), nil
+}
@@ -0,0 +1,251 @@
+package catalog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		dirs    []string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			dirs: []string{"../mock/layers/base"},
+			key:  "en.messages.hello",
+			want: "hello",
+		},
+		{
+			name: "layers",
+			dirs: []string{"../mock/layers/base", "../mock/layers/brand"},
+			key:  "en.messages.hello",
+			want: "hello from brand",
+		},
+		{
+			name:    "invalid",
+			dirs:    []string{"../mock"},
+			wantErr: true,
+		},
+		{
+			name:    "missing",
+			dirs:    []string{"../mock/layers/missing"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(tt.dirs...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got, _ := c.Lookup(tt.key); got != tt.want {
+				t.Errorf("Lookup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalog_Lookup(t *testing.T) {
+	c, err := NewFS(fstest.MapFS{
+		"locales/en/messages.json": {Data: []byte(`{"hello": "hello"}`)},
+	}, "locales/")
+	if err != nil {
+		t.Fatalf("NewFS() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		key    string
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "valid",
+			key:    "en.messages.hello",
+			want:   "hello",
+			wantOk: true,
+		},
+		{
+			name: "no key",
+			key:  "en.messages.hello2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := c.Lookup(tt.key)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("Lookup() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestOptions_NewFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"res/values/strings.xml":     {Data: []byte(`<resources><string name="hi">hi</string></resources>`)},
+		"res/values-es/strings.xml":  {Data: []byte(`<resources><string name="hi">hola</string></resources>`)},
+		"res/messages.properties":    {Data: []byte("bye=bye")},
+		"res/messages_es.properties": {Data: []byte("bye=adiós")},
+	}
+	if _, err := NewFS(fsys, "res"); err == nil {
+		t.Errorf("NewFS() error = nil, want the base bundle to need a base locale")
+	}
+
+	c, err := Options{BaseLocale: "en"}.NewFS(fsys, "res")
+	if err != nil {
+		t.Fatalf("NewFS() error = %v", err)
+	}
+	for key, want := range map[string]string{
+		"en.strings.hi":   "hi",
+		"es.strings.hi":   "hola",
+		"en.messages.bye": "bye",
+		"es.messages.bye": "adiós",
+	} {
+		if got, _ := c.Lookup(key); got != want {
+			t.Errorf("Lookup(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestCatalog_Reload(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en/messages.json": {Data: []byte(`{"hello": "hello"}`)},
+	}
+	c, err := NewFS(fsys, ".")
+	if err != nil {
+		t.Fatalf("NewFS() error = %v", err)
+	}
+
+	wg := sync.WaitGroup{}
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					if got, _ := c.Lookup("en.messages.hello"); got != "hello" && got != "hi" {
+						t.Errorf("Lookup() = %v during reload", got)
+					}
+				}
+			}
+		}()
+	}
+
+	fsys["en/messages.json"] = &fstest.MapFile{Data: []byte(`{"hello": "hi"}`)}
+	if err := c.Reload(); err != nil {
+		t.Errorf("Reload() error = %v", err)
+	}
+	close(done)
+	wg.Wait()
+
+	if got, _ := c.Lookup("en.messages.hello"); got != "hi" {
+		t.Errorf("Lookup() after reload = %v, want %v", got, "hi")
+	}
+
+	fsys["en/messages.json"] = &fstest.MapFile{Data: []byte(`{"hello": `)}
+	if err := c.Reload(); err == nil {
+		t.Errorf("Reload() of invalid file error = nil")
+	}
+	if got, _ := c.Lookup("en.messages.hello"); got != "hi" {
+		t.Errorf("Lookup() after failed reload = %v, want %v", got, "hi")
+	}
+}
+
+func TestCatalog_Watch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "catalog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "en", "messages.json")
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(file, []byte(`{"hello": "hello"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Watch(ctx, 10*time.Millisecond, func(err error) {
+		t.Errorf("Watch() error = %v", err)
+	})
+
+	if err := ioutil.WriteFile(file, []byte(`{"hello": "hello again"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, c, "en.messages.hello", "hello again")
+}
+
+func TestCatalog_WatchSignal(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en/messages.json": {Data: []byte(`{"hello": "hello"}`)},
+	}
+	c, err := NewFS(fsys, ".")
+	if err != nil {
+		t.Fatalf("NewFS() error = %v", err)
+	}
+
+	// Stop SIGHUP terminating the test before Watch is notified of it.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// Same size and modification time, so only picked up by the signal.
+	fsys["en/messages.json"] = &fstest.MapFile{Data: []byte(`{"hello": "hallo"}`)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Watch(ctx, time.Hour, nil)
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for got, _ := c.Lookup("en.messages.hello"); got != "hallo" && time.Now().Before(deadline); got, _ = c.Lookup("en.messages.hello") {
+		if err := p.Signal(syscall.SIGHUP); err != nil {
+			t.Skipf("sending SIGHUP: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	waitFor(t, c, "en.messages.hello", "hallo")
+}
+
+func waitFor(t *testing.T, c *Catalog, key, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if got, _ := c.Lookup(key); got == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	got, _ := c.Lookup(key)
+	t.Errorf("Lookup() = %v, want %v", got, want)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// configFileNames are the config files looked for in the working directory,
+// in order of precedence.
+var configFileNames = []string{"go-localize.yaml", "go-localize.yml", "go-localize.toml"}
+
+// config is the project configuration file. Every value can be overridden
+// using the matching CLI flag.
+type config struct {
+	Inputs             []string `yaml:"inputs" toml:"inputs"`
+	Output             string   `yaml:"output" toml:"output"`
+	Package            string   `yaml:"package" toml:"package"`
+	Filename           string   `yaml:"filename" toml:"filename"`
+	Mode               string   `yaml:"mode" toml:"mode"`
+	BuildTags          bool     `yaml:"build_tags" toml:"build_tags"`
+	Fake               bool     `yaml:"fake" toml:"fake"`
+	BaseLocale         string   `yaml:"base_locale" toml:"base_locale"`
+	Validations        []string `yaml:"validations" toml:"validations"`
+	Pseudo             string   `yaml:"pseudo" toml:"pseudo"`
+	PseudoLocale       string   `yaml:"pseudo_locale" toml:"pseudo_locale"`
+	PseudoPadding      int      `yaml:"pseudo_padding" toml:"pseudo_padding"`
+	Bundles            string   `yaml:"bundles" toml:"bundles"`
+	BundleFormat       string   `yaml:"bundle_format" toml:"bundle_format"`
+	BundlePlaceholders string   `yaml:"bundle_placeholders" toml:"bundle_placeholders"`
+	Typings            bool     `yaml:"typings" toml:"typings"`
+	Android            string   `yaml:"android" toml:"android"`
+	Apple              string   `yaml:"apple" toml:"apple"`
+	ARB                string   `yaml:"arb" toml:"arb"`
+}
+
+// loadConfig loads the config file at path, or the first config file found
+// in the working directory if path is empty. A missing config file is only an
+// error if the path was given explicitly.
+func loadConfig(path string) (config, error) {
+	cfg := config{}
+	if path == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return cfg, err
+		}
+		path = findConfig(wd)
+		if path == "" {
+			return cfg, nil
+		}
+	}
+
+	byteValue, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(byteValue, &cfg)
+	case ".toml":
+		var meta toml.MetaData
+		meta, err = toml.Decode(string(byteValue), &cfg)
+		if err == nil && len(meta.Undecoded()) > 0 {
+			err = fmt.Errorf("unknown config keys %v", meta.Undecoded())
+		}
+	default:
+		err = fmt.Errorf("unsupported config file type %v", filepath.Ext(path))
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("%v: %v", path, err)
+	}
+
+	// Paths in the config are relative to the config file, not to wherever
+	// go-localize happens to be run from.
+	base := filepath.Dir(path)
+	for i, input := range cfg.Inputs {
+		cfg.Inputs[i] = resolveConfigPath(base, input)
+	}
+	cfg.Output = resolveConfigPath(base, cfg.Output)
+	cfg.Bundles = resolveConfigPath(base, cfg.Bundles)
+	cfg.Android = resolveConfigPath(base, cfg.Android)
+	cfg.Apple = resolveConfigPath(base, cfg.Apple)
+	cfg.ARB = resolveConfigPath(base, cfg.ARB)
+
+	return cfg, nil
+}
+
+func findConfig(dir string) string {
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+func resolveConfigPath(base, path string) string {
+	if path == "" || filepath.IsAbs(path) || base == "." {
+		return path
+	}
+	return filepath.Join(base, path)
+}
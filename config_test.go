@@ -0,0 +1,101 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_loadConfig(t *testing.T) {
+	type args struct {
+		path string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    config
+		wantErr bool
+	}{
+		{
+			name: "no config",
+			args: args{""},
+			want: config{},
+		},
+		{
+			name: "yaml",
+			args: args{"mock/config/yaml/go-localize.yaml"},
+			want: config{
+				Inputs: []string{"mock/config/yaml/localizations_src", "mock/config/yaml/white_label"},
+				Output: "mock/config/yaml/localizations",
+			},
+		},
+		{
+			name: "toml",
+			args: args{"mock/config/toml/go-localize.toml"},
+			want: config{
+				Inputs: []string{"mock/config/toml/localizations_src"},
+				Output: "mock/config/toml/localizations",
+			},
+		},
+		{
+			name:    "unknown key",
+			args:    args{"mock/config/invalid/go-localize.yaml"},
+			wantErr: true,
+		},
+		{
+			name:    "not exist",
+			args:    args{"mock/config/go-localize.yaml"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported type",
+			args:    args{"mock/valid.json"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadConfig(tt.args.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) && !tt.wantErr {
+				t.Errorf("loadConfig() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_findConfig(t *testing.T) {
+	type args struct {
+		dir string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "yaml",
+			args: args{"mock/config/yaml"},
+			want: "mock/config/yaml/go-localize.yaml",
+		},
+		{
+			name: "toml",
+			args: args{"mock/config/toml"},
+			want: "mock/config/toml/go-localize.toml",
+		},
+		{
+			name: "none",
+			args: args{"mock/dir"},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findConfig(tt.args.dir); got != tt.want {
+				t.Errorf("findConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package embedded
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLocalizer_Get(t1 *testing.T) {
+	type fields struct {
+		Locale         string
+		FallbackLocale string
+	}
+	type args struct {
+		key          string
+		replacements []*Replacements
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   string
+	}{
+		{
+			name:   "field",
+			fields: fields{Locale: "en", FallbackLocale: "es"},
+			args:   args{key: "messages.hello"},
+			want:   "hello",
+		},
+		{
+			name:   "fallback",
+			fields: fields{Locale: "ru", FallbackLocale: "es"},
+			args:   args{key: "customer.messages.hello"},
+			want:   "hello customer!",
+		},
+		{
+			name:   "no key",
+			fields: fields{Locale: "en", FallbackLocale: "es"},
+			args:   args{key: "messages.hello2"},
+			want:   "messages.hello2",
+		},
+		{
+			name:   "invalid locale",
+			fields: fields{Locale: "../en", FallbackLocale: "../es"},
+			args:   args{key: "messages.hello"},
+			want:   "messages.hello",
+		},
+		{
+			name:   "valid replacements",
+			fields: fields{Locale: "es", FallbackLocale: "en"},
+			args: args{
+				key:          "messages.hello_my_name_is",
+				replacements: []*Replacements{{"name": "test"}},
+			},
+			want: "Hola, mi nombre es test",
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := New(tt.fields.Locale, tt.fields.FallbackLocale)
+			if got := t.Get(tt.args.key, tt.args.replacements...); got != tt.want {
+				t1.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizer_GetConcurrent(t1 *testing.T) {
+	t := New("en", "es")
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := t.GetWithLocale("es", "messages.hello"); got != "Hola" {
+				t1.Errorf("GetWithLocale() = %v, want %v", got, "Hola")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func Test_loadLocale(t *testing.T) {
+	for _, locale := range []string{"xx-unknown", "../en", "en"} {
+		loadLocale(locale)
+	}
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	for _, locale := range []string{"xx-unknown", "../en"} {
+		if _, ok := locales[locale]; ok {
+			t.Errorf("loadLocale() kept %q, which has no localizations", locale)
+		}
+	}
+	if len(locales["en"]) == 0 {
+		t.Errorf("loadLocale() didn't keep en")
+	}
+}
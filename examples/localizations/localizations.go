@@ -1,40 +1,181 @@
 // Code generated by go-localize; DO NOT EDIT.
 // This file was generated by robots at
-// 2020-11-11 22:23:20.406208 -0800 PST m=+0.001173998
+// 2026-10-19 17:15:45.987087685 +0000 UTC m=+0.002284552
 
 package localizations
 
 import (
 	"bytes"
 	"fmt"
+	htmltemplate "html/template"
+	"strconv"
 	"strings"
 	"text/template"
+	"text/template/parse"
 )
 
 var localizations = map[string]string{
-	"en.messages.hello": "hello",
-	"en.messages.hello_firstname_lastname": "Hello {{.firstname}} {{.lastname}}",
-	"en.messages.hello_my_name_is": "Hello my name is {{.name}}",
-	"en.messages.how_are_you": "How are you?",
-	"en.messages.whats_your_name": "What's your name?",
-	"es.customer.messages.hello": "hello customer!",
-	"es.messages.hello": "Hola",
-	"es.messages.hello_my_name_is": "Hola, mi nombre es {{.name}}",
-	"es.messages.how_are_you": "¿Cómo estás?",
-	"es.messages.whats_your_name": "¿Cuál es tu nombre?",
+	"en-XA.app.cartItems.0":                   "[Ýöûŕ çåŕţ îš éṁþţý~~~~~~]",
+	"en-XA.app.cartItems.one":                 "[Öñé îţéṁ îñ ýöûŕ çåŕţ~~~~~~~]",
+	"en-XA.app.cartItems.other":               "[{{.count}} îţéṁš îñ ýöûŕ çåŕţ~~~~~~]",
+	"en-XA.app.greeting":                      "[Ŵéļçöṁé ƀåçķ, {{.name}}!~~~~~]",
+	"en-XA.messages.hello":                    "[ĥéļļö~~]",
+	"en-XA.messages.hello_firstname_lastname": "[Ĥéļļö {{.firstname}} {{.lastname}}~~~]",
+	"en-XA.messages.hello_my_name_is":         "[Ĥéļļö ṁý ñåṁé îš {{.name}}~~~~~~]",
+	"en-XA.messages.how_are_you":              "[Ĥöŵ åŕé ýöû?~~~~]",
+	"en-XA.messages.ranked.few":               "[{{.n}}ŕð þļåçé~~~]",
+	"en-XA.messages.ranked.one":               "[{{.n}}šţ þļåçé~~~]",
+	"en-XA.messages.ranked.other":             "[{{.n}}ţĥ þļåçé~~~]",
+	"en-XA.messages.ranked.two":               "[{{.n}}ñð þļåçé~~~]",
+	"en-XA.messages.updated_profile.female":   "[{{.user}} ûþðåţéð ĥéŕ þŕöƒîļé~~~~~~]",
+	"en-XA.messages.updated_profile.male":     "[{{.user}} ûþðåţéð ĥîš þŕöƒîļé~~~~~~]",
+	"en-XA.messages.updated_profile.other":    "[{{.user}} ûþðåţéð ţĥéîŕ þŕöƒîļé~~~~~~~]",
+	"en-XA.messages.whats_your_name":          "[Ŵĥåţ'š ýöûŕ ñåṁé?~~~~~~]",
+	"en-XA.strings.unread.one":                "[{{.arg1}} ûñŕéåð ṁéššåĝé~~~~~]",
+	"en-XA.strings.unread.other":              "[{{.arg1}} ûñŕéåð ṁéššåĝéš~~~~~]",
+	"en.app.cartItems.0":                      "Your cart is empty",
+	"en.app.cartItems.one":                    "One item in your cart",
+	"en.app.cartItems.other":                  "{{.count}} items in your cart",
+	"en.app.greeting":                         "Welcome back, {{.name}}!",
+	"en.messages.hello":                       "hello",
+	"en.messages.hello_firstname_lastname":    "Hello {{.firstname}} {{.lastname}}",
+	"en.messages.hello_my_name_is":            "Hello my name is {{.name}}",
+	"en.messages.how_are_you":                 "How are you?",
+	"en.messages.ranked.few":                  "{{.n}}rd place",
+	"en.messages.ranked.one":                  "{{.n}}st place",
+	"en.messages.ranked.other":                "{{.n}}th place",
+	"en.messages.ranked.two":                  "{{.n}}nd place",
+	"en.messages.updated_profile.female":      "{{.user}} updated her profile",
+	"en.messages.updated_profile.male":        "{{.user}} updated his profile",
+	"en.messages.updated_profile.other":       "{{.user}} updated their profile",
+	"en.messages.whats_your_name":             "What's your name?",
+	"en.strings.unread.one":                   "{{.arg1}} unread message",
+	"en.strings.unread.other":                 "{{.arg1}} unread messages",
+	"es.app.cartItems.0":                      "Tu carrito está vacío",
+	"es.app.cartItems.one":                    "Un artículo en tu carrito",
+	"es.app.cartItems.other":                  "{{.count}} artículos en tu carrito",
+	"es.app.greeting":                         "¡Hola de nuevo, {{.name}}!",
+	"es.customer.messages.hello":              "hello customer!",
+	"es.messages.hello":                       "Hola",
+	"es.messages.hello_my_name_is":            "Hola, mi nombre es {{.name}}",
+	"es.messages.how_are_you":                 "¿Cómo estás?",
+	"es.messages.ranked.other":                "{{.n}}.º puesto",
+	"es.messages.updated_profile.female":      "{{.user}} actualizó su perfil como autora",
+	"es.messages.updated_profile.other":       "{{.user}} actualizó su perfil",
+	"es.messages.whats_your_name":             "¿Cuál es tu nombre?",
+}
+
+// KeyMetadata describes a key, from the metadata of its translation files,
+// e.g. the @key blocks of ARB files.
+type KeyMetadata struct {
+	// Description is what the key is for, e.g. for translators.
+	Description string
+	// Selector is the replacement the select cases of the key are chosen by,
+	// e.g. count, for GetSelect, which GetPlural sets to its count.
+	Selector string
+	// Placeholders are the replacements the key substitutes, by name.
+	Placeholders map[string]PlaceholderMetadata
+}
+
+// PlaceholderMetadata describes a replacement of a key.
+type PlaceholderMetadata struct {
+	// Type is the type of the replacement, e.g. String, int or DateTime.
+	Type string
+	// Example is an example value of the replacement.
+	Example string
+	// Format is how the replacement is formatted, e.g. compact or yMd.
+	Format string
+}
+
+// Metadata is the metadata of the keys that have any, keyed without the
+// locale, e.g. app.hello.
+var Metadata = map[string]KeyMetadata{
+	"app.cartItems": {
+		Description: "Number of items in the shopping cart",
+		Selector:    "count",
+		Placeholders: map[string]PlaceholderMetadata{
+			"count": {
+				Type:    "int",
+				Example: "",
+				Format:  "",
+			},
+		},
+	},
+	"app.greeting": {
+		Description: "Greeting shown on the home page",
+		Selector:    "",
+		Placeholders: map[string]PlaceholderMetadata{
+			"name": {
+				Type:    "String",
+				Example: "Bob",
+				Format:  "",
+			},
+		},
+	},
+	"strings.unread": {
+		Description: "",
+		Selector:    "arg1",
+	},
+}
+
+// pluralSelector returns the replacement GetPlural sets to its count besides
+// n, the selector of the key's metadata, e.g. count, or otherwise arg1.
+func pluralSelector(key string) string {
+	if m, ok := Metadata[key]; ok && m.Selector != "" {
+		return m.Selector
+	}
+	return "arg1"
 }
 
 type Replacements map[string]interface{}
 
+// Source provides localizations keyed including the locale, e.g.
+// en.messages.hello, in place of the generated localizations.
+type Source interface {
+	Lookup(key string) (string, bool)
+}
+
+// Translator is implemented by Localizer, so code can depend on the interface
+// and swap in a test double.
+type Translator interface {
+	Get(key string, replacements ...*Replacements) string
+	GetWithLocale(locale, key string, replacements ...*Replacements) string
+	Lookup(key string, replacements ...*Replacements) (string, bool)
+	GetHTML(key string, replacements ...*Replacements) htmltemplate.HTML
+	Getf(key string, args ...interface{}) string
+	GetSelect(key, selector string, replacements ...*Replacements) string
+	GetOrdinal(key string, n int, replacements ...*Replacements) string
+	GetPlural(key string, n int, replacements ...*Replacements) string
+	Direction() Direction
+}
+
+// Direction is the direction a locale's text is written in, e.g. for the dir
+// HTML attribute.
+type Direction string
+
+const (
+	LeftToRight Direction = "ltr"
+	RightToLeft Direction = "rtl"
+)
+
+var _ Translator = Localizer{}
+
+// Localizer is safe for concurrent use. The localizations it is created with
+// are shared by every Localizer and never modified, use WithOverrides to
+// change localizations for a single Localizer.
 type Localizer struct {
-	Locale	 string
+	Locale         string
 	FallbackLocale string
-	Localizations  map[string]string
+	localizations  map[string]string
+	overrides      map[string]string
+	source         Source
+	scope          string
+	isolate        bool
 }
 
 func New(locale string, fallbackLocale string) *Localizer {
 	t := &Localizer{Locale: locale, FallbackLocale: fallbackLocale}
-	t.Localizations = localizations
+	t.localizations = localizations
 	return t
 }
 
@@ -54,51 +195,915 @@ func (t Localizer) SetFallbackLocale(fallback string) Localizer {
 	return t
 }
 
+// WithSource returns the Localizer looking up localizations in source, such as
+// a catalog loaded at runtime, instead of the generated localizations.
+func (t Localizer) WithSource(source Source) Localizer {
+	t.source = source
+	return t
+}
+
+// Scope returns the Localizer prefixing keys with prefix, e.g. with the
+// customer.messages scope Get("hello") gets customer.messages.hello. Scopes
+// compose, so scoping a scoped Localizer appends to its prefix.
+func (t Localizer) Scope(prefix string) Localizer {
+	prefix = strings.Trim(prefix, ".")
+	if prefix != "" {
+		t.scope += prefix + "."
+	}
+	return t
+}
+
+// WithBidiIsolation returns the Localizer wrapping replacements and Getf
+// arguments in the FSI and PDI bidi isolate characters when rendering for a
+// right-to-left locale, so left-to-right values like emails and order IDs
+// don't garble the text around them. Only the output of replacements is
+// isolated, so they can still be compared in template logic, e.g. with eq.
+func (t Localizer) WithBidiIsolation() Localizer {
+	t.isolate = true
+	return t
+}
+
+// Direction returns the direction of the Localizer's locale.
+func (t Localizer) Direction() Direction {
+	return direction(t.Locale)
+}
+
+// WithOverrides returns the Localizer with the overrides, keyed including the
+// locale, e.g. en.messages.hello, taking precedence over its localizations.
+// The overrides are copied, so neither t nor overrides are modified.
+func (t Localizer) WithOverrides(overrides map[string]string) Localizer {
+	merged := make(map[string]string, len(t.overrides)+len(overrides))
+	for key, value := range t.overrides {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		merged[key] = value
+	}
+	t.overrides = merged
+	return t
+}
+
 func (t Localizer) GetWithLocale(locale, key string, replacements ...*Replacements) string {
-	str, ok := t.Localizations[t.getLocalizationKey(locale, key)]
+	key = t.scope + key
+	str, ok := t.resolve(locale, key, 0, replacements...)
 	if !ok {
-		str, ok = t.Localizations[t.getLocalizationKey(t.FallbackLocale, key)]
-		if !ok {
-			return key
+		return key
+	}
+	return str
+}
+
+func (t Localizer) Get(key string, replacements ...*Replacements) string {
+	str := t.GetWithLocale(t.Locale, key, replacements...)
+	return str
+}
+
+// Lookup is Get, but reports whether key was found in the locale or the
+// fallback locale instead of returning the key.
+func (t Localizer) Lookup(key string, replacements ...*Replacements) (string, bool) {
+	return t.resolve(t.Locale, t.scope+key, 0, replacements...)
+}
+
+// GetHTML is Get, but escaped for use in HTML. Replacements are always
+// escaped, the localization itself only when key doesn't end in _html, which
+// marks the markup in its localizations as trusted.
+func (t Localizer) GetHTML(key string, replacements ...*Replacements) htmltemplate.HTML {
+	key = t.scope + key
+	str, ok := t.resolveHTML(t.Locale, key, 0, replacements...)
+	if !ok {
+		return htmltemplate.HTML(htmltemplate.HTMLEscapeString(key))
+	}
+	return str
+}
+
+// Getf returns the localization of key formatted with args using fmt, for
+// localizations using verbs like %s or %[2]d instead of replacements.
+func (t Localizer) Getf(key string, args ...interface{}) string {
+	key = t.scope + key
+	str, ok := t.resolve(t.Locale, key, 0)
+	if !ok {
+		return key
+	}
+	if t.isolate && direction(t.Locale) == RightToLeft {
+		// Width and precision arguments, e.g. of %*d, must stay ints.
+		stars := starArgs(str)
+		isolatedArgs := make([]interface{}, len(args))
+		for i, arg := range args {
+			isolatedArgs[i] = arg
+			if !stars[i] {
+				isolatedArgs[i] = isolated{arg}
+			}
+		}
+		args = isolatedArgs
+	}
+	return fmt.Sprintf(str, args...)
+}
+
+// GetSelect returns the select case of key matching selector, e.g. for
+// grammatical gender, or otherwise its other case. Select cases are declared
+// as a nested object with a required other case in the translation files, so
+// with
+//
+//	updated: {female: "updated her profile", other: "updated their profile"}
+//
+// GetSelect("messages.updated", "female") gets messages.updated.female.
+func (t Localizer) GetSelect(key, selector string, replacements ...*Replacements) string {
+	key = t.scope + key
+	for _, locale := range []string{t.Locale, t.FallbackLocale} {
+		for _, c := range []string{selector, "other"} {
+			if str, ok := t.lookup(locale, key+"."+c); ok {
+				return t.render(locale, str, 0, replacements...)
+			}
+		}
+	}
+	return key
+}
+
+// GetOrdinal returns the case of key for the CLDR ordinal category of n in
+// the locale, e.g. one for 1st, two for 2nd, few for 3rd and other for 4th in
+// English, or otherwise its other case. Cases are declared like select cases,
+// and n is available to them as the n replacement.
+func (t Localizer) GetOrdinal(key string, n int, replacements ...*Replacements) string {
+	key = t.scope + key
+	ordinal := Replacements{"n": n}
+	replacements = append([]*Replacements{&ordinal}, replacements...)
+	for _, locale := range []string{t.Locale, t.FallbackLocale} {
+		for _, c := range []string{ordinalCategory(locale, n), "other"} {
+			if str, ok := t.lookup(locale, key+"."+c); ok {
+				return t.render(locale, str, 0, replacements...)
+			}
+		}
+	}
+	return key
+}
+
+// GetPlural returns the case of key for the count n, e.g. items.0 if there is
+// a case for exactly n, otherwise the case of the CLDR cardinal category of n
+// in the locale, e.g. one for 1 and other for 2 in English, or otherwise its
+// other case. Plurals loaded from Android, Apple, ARB and Fluent files are
+// declared this way. n is available to the cases as the n replacement and as
+// the replacement they are selected by, e.g. count in ARB files or arg1 for
+// %d in Android files, unless replacements set it.
+func (t Localizer) GetPlural(key string, n int, replacements ...*Replacements) string {
+	key = t.scope + key
+	plural := Replacements{"n": n, pluralSelector(key): n}
+	replacements = append([]*Replacements{&plural}, replacements...)
+	for _, locale := range []string{t.Locale, t.FallbackLocale} {
+		for _, c := range []string{fmt.Sprint(n), cardinalCategory(locale, n), "other"} {
+			if str, ok := t.lookup(locale, key+"."+c); ok {
+				return t.render(locale, str, 0, replacements...)
+			}
 		}
 	}
+	return key
+}
+
+// resolve looks up key in locale or otherwise the fallback locale, and
+// replaces its substitutions and references to other keys.
+func (t Localizer) resolve(locale, key string, depth int, replacements ...*Replacements) (string, bool) {
+	str, ok := t.find(locale, key)
+	if !ok {
+		return "", false
+	}
+	return t.render(locale, str, depth, replacements...), true
+}
 
-        // If the str doesn't have any substitutions, no need to
-        // template.Execute.
+// render replaces the substitutions and references to other keys of str.
+func (t Localizer) render(locale, str string, depth int, replacements ...*Replacements) string {
+	// If the str doesn't have any substitutions, no need to
+	// template.Execute.
 	if strings.Index(str, "}}") == -1 {
-                return str
-        }
+		return str
+	}
 
-	return t.replace(str, replacements...)
+	return t.replace(locale, str, depth, replacements...)
 }
 
-func (t Localizer) Get(key string, replacements ...*Replacements) string {
-	str := t.GetWithLocale(t.Locale, key, replacements...)
-	return str
+// resolveHTML is resolve, but escaped for use in HTML.
+func (t Localizer) resolveHTML(locale, key string, depth int, replacements ...*Replacements) (htmltemplate.HTML, bool) {
+	if !strings.HasSuffix(key, "_html") {
+		str, ok := t.resolve(locale, key, depth, replacements...)
+		return htmltemplate.HTML(htmltemplate.HTMLEscapeString(str)), ok
+	}
+
+	str, ok := t.find(locale, key)
+	if !ok {
+		return "", false
+	}
+	if strings.Index(str, "}}") == -1 {
+		return htmltemplate.HTML(str), true
+	}
+
+	return t.replaceHTML(locale, str, depth, replacements...), true
+}
+
+// find looks up key in locale or otherwise the fallback locale.
+func (t Localizer) find(locale, key string) (string, bool) {
+	str, ok := t.lookup(locale, key)
+	if !ok {
+		str, ok = t.lookup(t.FallbackLocale, key)
+	}
+	return str, ok
+}
+
+func (t Localizer) lookup(locale, key string) (string, bool) {
+	if str, ok := t.overrides[t.getLocalizationKey(locale, key)]; ok {
+		return str, true
+	}
+	if t.source != nil {
+		return t.source.Lookup(t.getLocalizationKey(locale, key))
+	}
+
+	str, ok := t.localizations[t.getLocalizationKey(locale, key)]
+	return str, ok
 }
 
 func (t Localizer) getLocalizationKey(locale string, key string) string {
 	return fmt.Sprintf("%v.%v", locale, key)
 }
 
-func (t Localizer) replace(str string, replacements ...*Replacements) string {
+// ordinalRules are the CLDR ordinal plural rules by language, returning the
+// category of n. Languages without a rule only use other.
+var ordinalRules = map[string]func(n int) string{
+	"en": func(n int) string {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 == 2 && n%100 != 12:
+			return "two"
+		case n%10 == 3 && n%100 != 13:
+			return "few"
+		}
+		return "other"
+	},
+	"fr":  ordinalOne,
+	"ga":  ordinalOne,
+	"hy":  ordinalOne,
+	"lo":  ordinalOne,
+	"ms":  ordinalOne,
+	"ro":  ordinalOne,
+	"vi":  ordinalOne,
+	"fil": ordinalOne,
+	"tl":  ordinalOne,
+	"it": func(n int) string {
+		if n == 11 || n == 8 || n == 80 || n == 800 {
+			return "many"
+		}
+		return "other"
+	},
+	"sv": func(n int) string {
+		if (n%10 == 1 || n%10 == 2) && n%100 != 11 && n%100 != 12 {
+			return "one"
+		}
+		return "other"
+	},
+	"hu": func(n int) string {
+		if n == 1 || n == 5 {
+			return "one"
+		}
+		return "other"
+	},
+	"ca": func(n int) string {
+		switch n {
+		case 1, 3:
+			return "one"
+		case 2:
+			return "two"
+		case 4:
+			return "few"
+		}
+		return "other"
+	},
+	"cy": func(n int) string {
+		switch n {
+		case 0, 7, 8, 9:
+			return "zero"
+		case 1:
+			return "one"
+		case 2:
+			return "two"
+		case 3, 4:
+			return "few"
+		case 5, 6:
+			return "many"
+		}
+		return "other"
+	},
+	"hi": ordinalHindi,
+	"gu": ordinalHindi,
+	"mr": func(n int) string {
+		switch n {
+		case 1:
+			return "one"
+		case 2, 3:
+			return "two"
+		case 4:
+			return "few"
+		}
+		return "other"
+	},
+	"bn": ordinalBengali,
+	"as": ordinalBengali,
+	"ne": func(n int) string {
+		if n >= 1 && n <= 4 {
+			return "one"
+		}
+		return "other"
+	},
+	"sq": func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n%10 == 4 && n%100 != 14:
+			return "many"
+		}
+		return "other"
+	},
+	"mk": func(n int) string {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 == 2 && n%100 != 12:
+			return "two"
+		case (n%10 == 7 || n%10 == 8) && n%100 != 17 && n%100 != 18:
+			return "many"
+		}
+		return "other"
+	},
+	"kk": func(n int) string {
+		if n%10 == 6 || n%10 == 9 || (n%10 == 0 && n != 0) {
+			return "many"
+		}
+		return "other"
+	},
+	"uk": func(n int) string {
+		if n%10 == 3 && n%100 != 13 {
+			return "few"
+		}
+		return "other"
+	},
+	"be": func(n int) string {
+		if (n%10 == 2 || n%10 == 3) && n%100 != 12 && n%100 != 13 {
+			return "few"
+		}
+		return "other"
+	},
+	"tk": func(n int) string {
+		if n%10 == 6 || n%10 == 9 || n == 10 {
+			return "few"
+		}
+		return "other"
+	},
+	"ka": func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n == 0 || (n%100 >= 2 && n%100 <= 20) || n%100 == 40 || n%100 == 60 || n%100 == 80:
+			return "many"
+		}
+		return "other"
+	},
+	"az": func(n int) string {
+		switch {
+		case n%10 == 1 || n%10 == 2 || n%10 == 5 || n%10 == 7 || n%10 == 8 ||
+			n%100 == 20 || n%100 == 50 || n%100 == 70 || n%100 == 80:
+			return "one"
+		case n%10 == 3 || n%10 == 4 || (n%1000 != 0 && n%100 == 0):
+			return "few"
+		case n == 0 || n%10 == 6 || n%100 == 40 || n%100 == 60 || n%100 == 90:
+			return "many"
+		}
+		return "other"
+	},
+}
+
+func ordinalOne(n int) string {
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func ordinalHindi(n int) string {
+	switch n {
+	case 1:
+		return "one"
+	case 2, 3:
+		return "two"
+	case 4:
+		return "few"
+	case 6:
+		return "many"
+	}
+	return "other"
+}
+
+func ordinalBengali(n int) string {
+	switch n {
+	case 1, 5, 7, 8, 9, 10:
+		return "one"
+	case 2, 3:
+		return "two"
+	case 4:
+		return "few"
+	case 6:
+		return "many"
+	}
+	return "other"
+}
+
+// ordinalCategory returns the CLDR ordinal category of n in locale, using the
+// rules of its language, e.g. en for en-GB.
+func ordinalCategory(locale string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	language := strings.ToLower(locale)
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	if rule, ok := ordinalRules[language]; ok {
+		return rule(n)
+	}
+	return "other"
+}
+
+// cardinalRules are the CLDR cardinal plural rules of whole numbers by
+// language, or by locale for the ones that differ from their language, e.g.
+// pt-pt, returning the category of n. Languages without a rule, e.g. ja or
+// zh, only use other.
+var cardinalRules = map[string]func(n int) string{
+	"af":    cardinalOne,
+	"bg":    cardinalOne,
+	"da":    cardinalOne,
+	"de":    cardinalOne,
+	"el":    cardinalOne,
+	"en":    cardinalOne,
+	"et":    cardinalOne,
+	"eu":    cardinalOne,
+	"fi":    cardinalOne,
+	"gl":    cardinalOne,
+	"hu":    cardinalOne,
+	"ka":    cardinalOne,
+	"nb":    cardinalOne,
+	"nl":    cardinalOne,
+	"nn":    cardinalOne,
+	"no":    cardinalOne,
+	"sq":    cardinalOne,
+	"sv":    cardinalOne,
+	"sw":    cardinalOne,
+	"tr":    cardinalOne,
+	"ur":    cardinalOne,
+	"ca":    cardinalOneMany,
+	"es":    cardinalOneMany,
+	"it":    cardinalOneMany,
+	"pt-pt": cardinalOneMany,
+	"fr":    cardinalZeroOneMany,
+	"pt":    cardinalZeroOneMany,
+	"am":    cardinalZeroOne,
+	"bn":    cardinalZeroOne,
+	"fa":    cardinalZeroOne,
+	"gu":    cardinalZeroOne,
+	"hi":    cardinalZeroOne,
+	"kn":    cardinalZeroOne,
+	"zu":    cardinalZeroOne,
+	"is":    cardinalOneTens,
+	"mk":    cardinalOneTens,
+	"be":    cardinalSlavic,
+	"ru":    cardinalSlavic,
+	"uk":    cardinalSlavic,
+	"bs":    cardinalSerbian,
+	"hr":    cardinalSerbian,
+	"sr":    cardinalSerbian,
+	"cs":    cardinalCzech,
+	"sk":    cardinalCzech,
+	"pl": func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		}
+		return "many"
+	},
+	"lt": func(n int) string {
+		switch {
+		case n%100 >= 11 && n%100 <= 19:
+			return "other"
+		case n%10 == 1:
+			return "one"
+		case n%10 >= 2:
+			return "few"
+		}
+		return "other"
+	},
+	"lv": func(n int) string {
+		switch {
+		case n%10 == 0 || (n%100 >= 11 && n%100 <= 19):
+			return "zero"
+		case n%10 == 1:
+			return "one"
+		}
+		return "other"
+	},
+	"ro": func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n == 0 || (n%100 >= 2 && n%100 <= 19):
+			return "few"
+		}
+		return "other"
+	},
+	"sl": func(n int) string {
+		switch n % 100 {
+		case 1:
+			return "one"
+		case 2:
+			return "two"
+		case 3, 4:
+			return "few"
+		}
+		return "other"
+	},
+	"he": func(n int) string {
+		switch n {
+		case 1:
+			return "one"
+		case 2:
+			return "two"
+		}
+		return "other"
+	},
+	"ar": func(n int) string {
+		switch {
+		case n == 0:
+			return "zero"
+		case n == 1:
+			return "one"
+		case n == 2:
+			return "two"
+		case n%100 >= 3 && n%100 <= 10:
+			return "few"
+		case n%100 >= 11:
+			return "many"
+		}
+		return "other"
+	},
+	"ga": func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n == 2:
+			return "two"
+		case n >= 3 && n <= 6:
+			return "few"
+		case n >= 7 && n <= 10:
+			return "many"
+		}
+		return "other"
+	},
+	"cy": func(n int) string {
+		switch n {
+		case 0:
+			return "zero"
+		case 1:
+			return "one"
+		case 2:
+			return "two"
+		case 3:
+			return "few"
+		case 6:
+			return "many"
+		}
+		return "other"
+	},
+}
+
+func cardinalOne(n int) string {
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func cardinalZeroOne(n int) string {
+	if n == 0 || n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func cardinalOneMany(n int) string {
+	switch {
+	case n == 1:
+		return "one"
+	case n != 0 && n%1000000 == 0:
+		return "many"
+	}
+	return "other"
+}
+
+func cardinalZeroOneMany(n int) string {
+	switch {
+	case n == 0 || n == 1:
+		return "one"
+	case n%1000000 == 0:
+		return "many"
+	}
+	return "other"
+}
+
+func cardinalOneTens(n int) string {
+	if n%10 == 1 && n%100 != 11 {
+		return "one"
+	}
+	return "other"
+}
+
+func cardinalSlavic(n int) string {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return "one"
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return "few"
+	}
+	return "many"
+}
+
+func cardinalSerbian(n int) string {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return "one"
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return "few"
+	}
+	return "other"
+}
+
+func cardinalCzech(n int) string {
+	switch {
+	case n == 1:
+		return "one"
+	case n >= 2 && n <= 4:
+		return "few"
+	}
+	return "other"
+}
+
+// cardinalCategory returns the CLDR cardinal category of n in locale, using
+// the rules of the locale if it has its own, e.g. pt-PT, or otherwise of its
+// language, e.g. en for en-GB.
+func cardinalCategory(locale string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	if rule, ok := cardinalRules[locale]; ok {
+		return rule(n)
+	}
+	language := locale
+	if i := strings.IndexByte(language, '-'); i >= 0 {
+		language = language[:i]
+	}
+	if rule, ok := cardinalRules[language]; ok {
+		return rule(n)
+	}
+	return "other"
+}
+
+// maxReferenceDepth limits how deeply references to other keys are followed, in
+// case overrides or a source added a cycle the generator could not detect.
+const maxReferenceDepth = 16
+
+func (t Localizer) replace(locale, str string, depth int, replacements ...*Replacements) string {
 	b := &bytes.Buffer{}
-	tmpl, err := template.New("").Parse(str)
+	tmpl, err := template.New("").Funcs(template.FuncMap{
+		// t is replaced by the localization of the key in the same locale,
+		// with the same fallback and replacements.
+		"t": func(key string) (string, error) {
+			if depth >= maxReferenceDepth {
+				return "", fmt.Errorf("references nested more than %d deep", maxReferenceDepth)
+			}
+			str, ok := t.resolve(locale, key, depth+1, replacements...)
+			if !ok {
+				return key, nil
+			}
+			return str, nil
+		},
+		"isolate": isolate,
+	}).Parse(str)
 	if err != nil {
 		return str
 	}
+	if t.isolate && direction(locale) == RightToLeft {
+		isolateActions(tmpl.Tree, tmpl.Tree.Root)
+	}
 
+	err = template.Must(tmpl, err).Execute(b, t.replacements(replacements))
+	if err != nil {
+		return str
+	}
+	buff := b.String()
+	return buff
+}
+
+// replaceHTML is replace using html/template, so the replacements and
+// references to keys not ending in _html are escaped for the context they are
+// used in.
+func (t Localizer) replaceHTML(locale, str string, depth int, replacements ...*Replacements) htmltemplate.HTML {
+	b := &bytes.Buffer{}
+	tmpl, err := htmltemplate.New("").Funcs(htmltemplate.FuncMap{
+		"t": func(key string) (htmltemplate.HTML, error) {
+			if depth >= maxReferenceDepth {
+				return "", fmt.Errorf("references nested more than %d deep", maxReferenceDepth)
+			}
+			str, ok := t.resolveHTML(locale, key, depth+1, replacements...)
+			if !ok {
+				return htmltemplate.HTML(htmltemplate.HTMLEscapeString(key)), nil
+			}
+			return str, nil
+		},
+		"isolate": isolate,
+	}).Parse(str)
+	if err != nil {
+		return htmltemplate.HTML(str)
+	}
+	if t.isolate && direction(locale) == RightToLeft {
+		isolateActions(tmpl.Tree, tmpl.Tree.Root)
+	}
+
+	err = tmpl.Execute(b, t.replacements(replacements))
+	if err != nil {
+		return htmltemplate.HTML(str)
+	}
+	return htmltemplate.HTML(b.String())
+}
+
+// replacements merges the replacements to render.
+func (t Localizer) replacements(replacements []*Replacements) Replacements {
 	replacementsMerge := Replacements{}
 	for _, replacement := range replacements {
 		for k, v := range *replacement {
 			replacementsMerge[k] = v
 		}
 	}
+	return replacementsMerge
+}
 
-	err = template.Must(tmpl, err).Execute(b, replacementsMerge)
-	if err != nil {
-		return str
+// isolateActions pipes the output of the actions in node through isolate,
+// other than references to other keys, which are isolated themselves.
+func isolateActions(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, node := range n.Nodes {
+			isolateActions(tree, node)
+		}
+	case *parse.IfNode:
+		isolateActions(tree, n.List)
+		isolateActions(tree, n.ElseList)
+	case *parse.RangeNode:
+		isolateActions(tree, n.List)
+		isolateActions(tree, n.ElseList)
+	case *parse.WithNode:
+		isolateActions(tree, n.List)
+		isolateActions(tree, n.ElseList)
+	case *parse.ActionNode:
+		// Declarations of variables don't output anything.
+		if len(n.Pipe.Decl) > 0 {
+			return
+		}
+		last := n.Pipe.Cmds[len(n.Pipe.Cmds)-1]
+		if id, ok := last.Args[0].(*parse.IdentifierNode); ok && id.Ident == "t" {
+			return
+		}
+		isolate := parse.NewIdentifier("isolate").SetTree(tree).SetPos(n.Pos)
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pos,
+			Args:     []parse.Node{isolate},
+		})
 	}
-	buff := b.String()
-	return buff
+}
+
+// isolate formats v wrapped in the FSI and PDI bidi isolate characters, so it
+// is displayed in its own direction.
+func isolate(v interface{}) string {
+	return "\u2068" + fmt.Sprint(v) + "\u2069"
+}
+
+// isolated formats its value wrapped in the FSI and PDI bidi isolate
+// characters, so it is displayed in its own direction.
+type isolated struct {
+	value interface{}
+}
+
+func (i isolated) Format(f fmt.State, verb rune) {
+	format := "%"
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			format += string(flag)
+		}
+	}
+	if width, ok := f.Width(); ok {
+		format += fmt.Sprint(width)
+	}
+	if precision, ok := f.Precision(); ok {
+		format += "." + fmt.Sprint(precision)
+	}
+	fmt.Fprintf(f, "\u2068"+format+string(verb)+"\u2069", i.value)
+}
+
+// starArgs returns the indexes of the arguments of format used as the width
+// or precision of a verb, e.g. 0 for %*d, including explicit argument indexes,
+// e.g. 1 for %[2]*[1]d.
+func starArgs(format string) map[int]bool {
+	stars := map[int]bool{}
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		// index parses an explicit argument index, e.g. [2].
+		index := func() {
+			if i >= len(format) || format[i] != '[' {
+				return
+			}
+			end := strings.IndexByte(format[i:], ']')
+			if end < 0 {
+				return
+			}
+			if n, err := strconv.Atoi(format[i+1 : i+end]); err == nil {
+				arg = n - 1
+			}
+			i += end + 1
+		}
+		// size parses a width or precision, consuming an argument if it is *.
+		size := func() {
+			index()
+			if i < len(format) && format[i] == '*' {
+				stars[arg] = true
+				arg++
+				i++
+				return
+			}
+			for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+				i++
+			}
+		}
+		size()
+		if i < len(format) && format[i] == '.' {
+			i++
+			size()
+		}
+		index()
+		arg++
+	}
+	return stars
+}
+
+// rtlLanguages and rtlScripts are the languages and scripts written
+// right-to-left.
+var (
+	rtlLanguages = map[string]bool{
+		"ar": true, "arc": true, "ckb": true, "dv": true, "fa": true, "he": true,
+		"iw": true, "ks": true, "nqo": true, "ps": true, "sd": true, "syr": true,
+		"ug": true, "ur": true, "yi": true,
+	}
+	rtlScripts = map[string]bool{
+		"adlm": true, "arab": true, "hebr": true, "mand": true, "nkoo": true,
+		"rohg": true, "samr": true, "syrc": true, "thaa": true,
+	}
+)
+
+// direction returns the direction of locale, from its script subtag if it
+// has one, e.g. az-Arab, or otherwise its language.
+func direction(locale string) Direction {
+	subtags := strings.FieldsFunc(strings.ToLower(locale), func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	if len(subtags) == 0 {
+		return LeftToRight
+	}
+	for _, subtag := range subtags[1:] {
+		if len(subtag) == 4 {
+			if rtlScripts[subtag] {
+				return RightToLeft
+			}
+			return LeftToRight
+		}
+	}
+	if rtlLanguages[subtags[0]] {
+		return RightToLeft
+	}
+	return LeftToRight
 }
@@ -1,15 +1,21 @@
 package localizations
 
 import (
+	"fmt"
+	htmltemplate "html/template"
 	"reflect"
+	"sync"
 	"testing"
+	"testing/fstest"
+
+	"github.com/m1/go-localize/catalog"
 )
 
 func TestLocalizer_Get(t1 *testing.T) {
 	type fields struct {
 		Locale         string
 		FallbackLocale string
-		Localizations  map[string]string
+		localizations  map[string]string
 	}
 	type args struct {
 		key          string
@@ -26,7 +32,7 @@ func TestLocalizer_Get(t1 *testing.T) {
 			fields: fields{
 				Locale:         "en",
 				FallbackLocale: "es",
-				Localizations:  localizations,
+				localizations:  localizations,
 			},
 			args: args{
 				key:          "messages.hello",
@@ -39,7 +45,7 @@ func TestLocalizer_Get(t1 *testing.T) {
 			fields: fields{
 				Locale:         "en",
 				FallbackLocale: "es",
-				Localizations:  localizations,
+				localizations:  localizations,
 			},
 			args: args{
 				key:          "messages.hello2",
@@ -52,7 +58,7 @@ func TestLocalizer_Get(t1 *testing.T) {
 			fields: fields{
 				Locale:         "en",
 				FallbackLocale: "es",
-				Localizations:  localizations,
+				localizations:  localizations,
 			},
 			args: args{
 				key: "messages.hello_my_name_is",
@@ -67,7 +73,7 @@ func TestLocalizer_Get(t1 *testing.T) {
 			fields: fields{
 				Locale:         "en",
 				FallbackLocale: "es",
-				Localizations:  localizations,
+				localizations:  localizations,
 			},
 			args: args{
 				key: "messages.hello_firstname_lastname",
@@ -84,7 +90,7 @@ func TestLocalizer_Get(t1 *testing.T) {
 			t := Localizer{
 				Locale:         tt.fields.Locale,
 				FallbackLocale: tt.fields.FallbackLocale,
-				Localizations:  tt.fields.Localizations,
+				localizations:  tt.fields.localizations,
 			}
 			if got := t.Get(tt.args.key, tt.args.replacements...); got != tt.want {
 				t1.Errorf("Get() = %v, want %v", got, tt.want)
@@ -97,7 +103,7 @@ func TestLocalizer_GetWithLocale(t1 *testing.T) {
 	type fields struct {
 		Locale         string
 		FallbackLocale string
-		Localizations  map[string]string
+		localizations  map[string]string
 	}
 	type args struct {
 		locale       string
@@ -115,7 +121,7 @@ func TestLocalizer_GetWithLocale(t1 *testing.T) {
 			fields: fields{
 				Locale:         "en",
 				FallbackLocale: "es",
-				Localizations:  localizations,
+				localizations:  localizations,
 			},
 			args: args{
 				locale:       "es",
@@ -130,7 +136,7 @@ func TestLocalizer_GetWithLocale(t1 *testing.T) {
 			t := Localizer{
 				Locale:         tt.fields.Locale,
 				FallbackLocale: tt.fields.FallbackLocale,
-				Localizations:  tt.fields.Localizations,
+				localizations:  tt.fields.localizations,
 			}
 			if got := t.GetWithLocale(tt.args.locale, tt.args.key, tt.args.replacements...); got != tt.want {
 				t1.Errorf("GetWithLocale() = %v, want %v", got, tt.want)
@@ -143,7 +149,7 @@ func TestLocalizer_SetFallbackLocale(t1 *testing.T) {
 	type fields struct {
 		Locale         string
 		FallbackLocale string
-		Localizations  map[string]string
+		localizations  map[string]string
 	}
 	type args struct {
 		fallback string
@@ -159,13 +165,13 @@ func TestLocalizer_SetFallbackLocale(t1 *testing.T) {
 			fields: fields{
 				Locale:         "en",
 				FallbackLocale: "es",
-				Localizations:  localizations,
+				localizations:  localizations,
 			},
 			args: args{fallback: "ru"},
 			want: Localizer{
 				Locale:         "en",
 				FallbackLocale: "ru",
-				Localizations:  localizations,
+				localizations:  localizations,
 			},
 		},
 	}
@@ -174,7 +180,7 @@ func TestLocalizer_SetFallbackLocale(t1 *testing.T) {
 			t := Localizer{
 				Locale:         tt.fields.Locale,
 				FallbackLocale: tt.fields.FallbackLocale,
-				Localizations:  tt.fields.Localizations,
+				localizations:  tt.fields.localizations,
 			}
 			if got := t.SetFallbackLocale(tt.args.fallback); !reflect.DeepEqual(got, tt.want) {
 				t1.Errorf("SetFallbackLocale() = %v, want %v", got, tt.want)
@@ -187,7 +193,7 @@ func TestLocalizer_SetLocale(t1 *testing.T) {
 	type fields struct {
 		Locale         string
 		FallbackLocale string
-		Localizations  map[string]string
+		localizations  map[string]string
 	}
 	type args struct {
 		locale string
@@ -203,13 +209,13 @@ func TestLocalizer_SetLocale(t1 *testing.T) {
 			fields: fields{
 				Locale:         "en",
 				FallbackLocale: "es",
-				Localizations:  localizations,
+				localizations:  localizations,
 			},
 			args: args{locale: "ru"},
 			want: Localizer{
 				Locale:         "ru",
 				FallbackLocale: "es",
-				Localizations:  localizations,
+				localizations:  localizations,
 			},
 		},
 	}
@@ -218,7 +224,7 @@ func TestLocalizer_SetLocale(t1 *testing.T) {
 			t := Localizer{
 				Locale:         tt.fields.Locale,
 				FallbackLocale: tt.fields.FallbackLocale,
-				Localizations:  tt.fields.Localizations,
+				localizations:  tt.fields.localizations,
 			}
 			if got := t.SetLocale(tt.args.locale); !reflect.DeepEqual(got, tt.want) {
 				t1.Errorf("SetLocale() = %v, want %v", got, tt.want)
@@ -231,7 +237,7 @@ func TestLocalizer_SetLocales(t1 *testing.T) {
 	type fields struct {
 		Locale         string
 		FallbackLocale string
-		Localizations  map[string]string
+		localizations  map[string]string
 	}
 	type args struct {
 		locale   string
@@ -248,13 +254,13 @@ func TestLocalizer_SetLocales(t1 *testing.T) {
 			fields: fields{
 				Locale:         "en",
 				FallbackLocale: "es",
-				Localizations:  localizations,
+				localizations:  localizations,
 			},
 			args: args{locale: "ru", fallback: "ru"},
 			want: Localizer{
 				Locale:         "ru",
 				FallbackLocale: "ru",
-				Localizations:  localizations,
+				localizations:  localizations,
 			},
 		},
 	}
@@ -263,7 +269,7 @@ func TestLocalizer_SetLocales(t1 *testing.T) {
 			t := Localizer{
 				Locale:         tt.fields.Locale,
 				FallbackLocale: tt.fields.FallbackLocale,
-				Localizations:  tt.fields.Localizations,
+				localizations:  tt.fields.localizations,
 			}
 			if got := t.SetLocales(tt.args.locale, tt.args.fallback); !reflect.DeepEqual(got, tt.want) {
 				t1.Errorf("SetLocales() = %v, want %v", got, tt.want)
@@ -276,7 +282,7 @@ func TestLocalizer_getLocalizationKey(t1 *testing.T) {
 	type fields struct {
 		Locale         string
 		FallbackLocale string
-		Localizations  map[string]string
+		localizations  map[string]string
 	}
 	type args struct {
 		locale string
@@ -293,7 +299,7 @@ func TestLocalizer_getLocalizationKey(t1 *testing.T) {
 			fields: fields{
 				Locale:         "en",
 				FallbackLocale: "en",
-				Localizations:  nil,
+				localizations:  nil,
 			},
 			args: args{
 				locale: "en",
@@ -307,7 +313,7 @@ func TestLocalizer_getLocalizationKey(t1 *testing.T) {
 			t := Localizer{
 				Locale:         tt.fields.Locale,
 				FallbackLocale: tt.fields.FallbackLocale,
-				Localizations:  tt.fields.Localizations,
+				localizations:  tt.fields.localizations,
 			}
 			if got := t.getLocalizationKey(tt.args.locale, tt.args.key); got != tt.want {
 				t1.Errorf("getLocalizationKey() = %v, want %v", got, tt.want)
@@ -320,12 +326,20 @@ func TestLocalizer_replace(t1 *testing.T) {
 	type fields struct {
 		Locale         string
 		FallbackLocale string
-		Localizations  map[string]string
+		localizations  map[string]string
 	}
 	type args struct {
+		locale       string
 		str          string
 		replacements []*Replacements
 	}
+	references := map[string]string{
+		"en.brand.name":     "Acme",
+		"en.brand.greeting": `Welcome to {{t "brand.name"}}, {{.name}}`,
+		"es.brand.greeting": `Bienvenido a {{t "brand.name"}}, {{.name}}`,
+		"en.cycle.a":        `{{t "cycle.b"}}`,
+		"en.cycle.b":        `{{t "cycle.a"}}`,
+	}
 	tests := []struct {
 		name   string
 		fields fields
@@ -337,23 +351,78 @@ func TestLocalizer_replace(t1 *testing.T) {
 			fields: fields{
 				Locale:         "en",
 				FallbackLocale: "es",
-				Localizations:  nil,
+				localizations:  nil,
 			},
 			args: args{
+				locale:       "en",
 				str:          "Hello {{.firstname}} {{.lastname}}",
 				replacements: []*Replacements{{"firstname": "test", "lastname": "test"}},
 			},
 			want: "Hello test test",
 		},
+		{
+			name: "reference",
+			fields: fields{
+				Locale:         "en",
+				FallbackLocale: "es",
+				localizations:  references,
+			},
+			args: args{
+				locale:       "en",
+				str:          `{{t "brand.greeting"}}!`,
+				replacements: []*Replacements{{"name": "steve"}},
+			},
+			want: "Welcome to Acme, steve!",
+		},
+		{
+			name: "reference fallback",
+			fields: fields{
+				Locale:         "es",
+				FallbackLocale: "en",
+				localizations:  references,
+			},
+			args: args{
+				locale:       "es",
+				str:          `{{t "brand.greeting"}}`,
+				replacements: []*Replacements{{"name": "steve"}},
+			},
+			want: "Bienvenido a Acme, steve",
+		},
+		{
+			name: "missing reference",
+			fields: fields{
+				Locale:         "en",
+				FallbackLocale: "es",
+				localizations:  references,
+			},
+			args: args{
+				locale: "en",
+				str:    `{{t "brand.missing"}}`,
+			},
+			want: "brand.missing",
+		},
+		{
+			name: "reference cycle",
+			fields: fields{
+				Locale:         "en",
+				FallbackLocale: "es",
+				localizations:  references,
+			},
+			args: args{
+				locale: "en",
+				str:    `{{t "cycle.a"}}`,
+			},
+			want: `{{t "cycle.a"}}`,
+		},
 	}
 	for _, tt := range tests {
 		t1.Run(tt.name, func(t1 *testing.T) {
 			t := Localizer{
 				Locale:         tt.fields.Locale,
 				FallbackLocale: tt.fields.FallbackLocale,
-				Localizations:  tt.fields.Localizations,
+				localizations:  tt.fields.localizations,
 			}
-			if got := t.replace(tt.args.str, tt.args.replacements...); got != tt.want {
+			if got := t.replace(tt.args.locale, tt.args.str, 0, tt.args.replacements...); got != tt.want {
 				t1.Errorf("replace() = %v, want %v", got, tt.want)
 			}
 		})
@@ -379,7 +448,7 @@ func TestNew(t *testing.T) {
 			want: &Localizer{
 				Locale:         "en",
 				FallbackLocale: "es",
-				Localizations:  localizations,
+				localizations:  localizations,
 			},
 		},
 	}
@@ -391,3 +460,664 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalizer_WithSource(t1 *testing.T) {
+	c, err := catalog.NewFS(fstest.MapFS{
+		"en/messages.json": {Data: []byte(`{"hello": "hello from disk"}`)},
+	}, ".")
+	if err != nil {
+		t1.Fatal(err)
+	}
+
+	type args struct {
+		locale string
+		key    string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "source",
+			args: args{locale: "en", key: "messages.hello"},
+			want: "hello from disk",
+		},
+		{
+			name: "not in source",
+			args: args{locale: "en", key: "messages.how_are_you"},
+			want: "messages.how_are_you",
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := New("en", "es").WithSource(c)
+			if got := t.GetWithLocale(tt.args.locale, tt.args.key); got != tt.want {
+				t1.Errorf("GetWithLocale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizer_WithOverrides(t1 *testing.T) {
+	type args struct {
+		overrides []map[string]string
+	}
+	tests := []struct {
+		name string
+		args args
+		key  string
+		want string
+	}{
+		{
+			name: "override",
+			args: args{overrides: []map[string]string{
+				{"en.messages.hello": "hi"},
+			}},
+			key:  "messages.hello",
+			want: "hi",
+		},
+		{
+			name: "later overrides",
+			args: args{overrides: []map[string]string{
+				{"en.messages.hello": "hi"},
+				{"en.messages.hello": "hey"},
+			}},
+			key:  "messages.hello",
+			want: "hey",
+		},
+		{
+			name: "not overridden",
+			args: args{overrides: []map[string]string{
+				{"en.messages.hello": "hi"},
+			}},
+			key:  "messages.how_are_you",
+			want: "How are you?",
+		},
+		{
+			name: "new key",
+			args: args{overrides: []map[string]string{
+				{"en.messages.new": "new"},
+			}},
+			key:  "messages.new",
+			want: "new",
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			original := New("en", "es")
+			t := *original
+			for _, overrides := range tt.args.overrides {
+				t = t.WithOverrides(overrides)
+			}
+			if got := t.Get(tt.key); got != tt.want {
+				t1.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+			if got := original.Get("messages.hello"); got != "hello" {
+				t1.Errorf("original Get() = %v, want %v", got, "hello")
+			}
+		})
+	}
+}
+
+func TestLocalizer_WithOverridesConcurrent(t1 *testing.T) {
+	t := New("en", "es").WithOverrides(map[string]string{"en.messages.hello": "hi"})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			o := t.WithOverrides(map[string]string{"en.messages.how_are_you": fmt.Sprint(i)})
+			if got := o.Get("messages.how_are_you"); got != fmt.Sprint(i) {
+				t1.Errorf("Get() = %v, want %v", got, i)
+			}
+			if got := t.Get("messages.hello"); got != "hi" {
+				t1.Errorf("Get() = %v, want %v", got, "hi")
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestLocalizer_Lookup(t1 *testing.T) {
+	tests := []struct {
+		name         string
+		locale       string
+		key          string
+		replacements []*Replacements
+		want         string
+		wantOk       bool
+	}{
+		{
+			name:   "found",
+			locale: "en",
+			key:    "messages.hello",
+			want:   "hello",
+			wantOk: true,
+		},
+		{
+			name:   "fallback",
+			locale: "fr",
+			key:    "messages.hello",
+			want:   "Hola",
+			wantOk: true,
+		},
+		{
+			name:         "replacements",
+			locale:       "en",
+			key:          "messages.hello_my_name_is",
+			replacements: []*Replacements{{"name": "steve"}},
+			want:         "Hello my name is steve",
+			wantOk:       true,
+		},
+		{
+			name:   "missing",
+			locale: "en",
+			key:    "messages.missing",
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := New(tt.locale, "es")
+			got, ok := t.Lookup(tt.key, tt.replacements...)
+			if got != tt.want || ok != tt.wantOk {
+				t1.Errorf("Lookup() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestLocalizer_Scope(t1 *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		key    string
+		want   string
+	}{
+		{
+			name:   "scope",
+			scopes: []string{"messages"},
+			key:    "hello",
+			want:   "Hola",
+		},
+		{
+			name:   "nested scopes",
+			scopes: []string{"customer", "messages"},
+			key:    "hello",
+			want:   "hello customer!",
+		},
+		{
+			name:   "dots trimmed",
+			scopes: []string{"customer.", ".messages"},
+			key:    "hello",
+			want:   "hello customer!",
+		},
+		{
+			name:   "empty scope",
+			scopes: []string{""},
+			key:    "messages.hello",
+			want:   "Hola",
+		},
+		{
+			name:   "missing key shows full key",
+			scopes: []string{"customer.messages"},
+			key:    "missing",
+			want:   "customer.messages.missing",
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := *New("es", "en")
+			for _, scope := range tt.scopes {
+				t = t.Scope(scope)
+			}
+			if got := t.Get(tt.key); got != tt.want {
+				t1.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizer_GetHTML(t1 *testing.T) {
+	overrides := map[string]string{
+		"en.brand.name":            "Tom & Jerry",
+		"en.brand.logo_html":       `<b>{{t "brand.name"}}</b>`,
+		"en.messages.welcome_html": `<a href="/u/{{.name}}" title="{{.name}}">{{t "brand.logo_html"}} {{.name}}</a>`,
+		"en.messages.markup":       "<b>{{.name}}</b>",
+		"en.messages.static_html":  "<i>hi</i>",
+	}
+	tests := []struct {
+		name         string
+		key          string
+		replacements []*Replacements
+		want         htmltemplate.HTML
+	}{
+		{
+			name:         "escaped",
+			key:          "messages.markup",
+			replacements: []*Replacements{{"name": "<script>"}},
+			want:         "&lt;b&gt;&lt;script&gt;&lt;/b&gt;",
+		},
+		{
+			name:         "trusted markup",
+			key:          "messages.welcome_html",
+			replacements: []*Replacements{{"name": `"<bob>"`}},
+			want:         `<a href="/u/%22%3cbob%3e%22" title="&#34;&lt;bob&gt;&#34;"><b>Tom &amp; Jerry</b> &#34;&lt;bob&gt;&#34;</a>`,
+		},
+		{
+			name: "trusted markup without replacements",
+			key:  "messages.static_html",
+			want: "<i>hi</i>",
+		},
+		{
+			name:         "fallback",
+			key:          "messages.hello_my_name_is",
+			replacements: []*Replacements{{"name": "<steve>"}},
+			want:         "Hello my name is &lt;steve&gt;",
+		},
+		{
+			name: "missing",
+			key:  "messages.<missing>",
+			want: "messages.&lt;missing&gt;",
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := New("en", "es").WithOverrides(overrides)
+			if got := t.GetHTML(tt.key, tt.replacements...); got != tt.want {
+				t1.Errorf("GetHTML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizer_Getf(t1 *testing.T) {
+	overrides := map[string]string{
+		"en.messages.items":   "%s has %d items",
+		"es.messages.items":   "%[2]d artículos de %[1]s",
+		"en.messages.percent": "100%% done",
+	}
+	tests := []struct {
+		name   string
+		locale string
+		key    string
+		args   []interface{}
+		want   string
+	}{
+		{
+			name:   "verbs",
+			locale: "en",
+			key:    "messages.items",
+			args:   []interface{}{"steve", 3},
+			want:   "steve has 3 items",
+		},
+		{
+			name:   "positional verbs",
+			locale: "es",
+			key:    "messages.items",
+			args:   []interface{}{"steve", 3},
+			want:   "3 artículos de steve",
+		},
+		{
+			name:   "no args",
+			locale: "en",
+			key:    "messages.percent",
+			want:   "100% done",
+		},
+		{
+			name:   "fallback",
+			locale: "fr",
+			key:    "messages.items",
+			args:   []interface{}{"steve", 3},
+			want:   "3 artículos de steve",
+		},
+		{
+			name:   "missing",
+			locale: "en",
+			key:    "messages.missing",
+			args:   []interface{}{"steve"},
+			want:   "messages.missing",
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := New(tt.locale, "es").WithOverrides(overrides)
+			if got := t.Getf(tt.key, tt.args...); got != tt.want {
+				t1.Errorf("Getf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizer_GetSelect(t1 *testing.T) {
+	tests := []struct {
+		name     string
+		locale   string
+		key      string
+		selector string
+		want     string
+	}{
+		{
+			name:     "case",
+			locale:   "en",
+			key:      "messages.updated_profile",
+			selector: "male",
+			want:     "steve updated his profile",
+		},
+		{
+			name:     "other",
+			locale:   "en",
+			key:      "messages.updated_profile",
+			selector: "unknown",
+			want:     "steve updated their profile",
+		},
+		{
+			name:     "other in locale before fallback case",
+			locale:   "es",
+			key:      "messages.updated_profile",
+			selector: "male",
+			want:     "steve actualizó su perfil",
+		},
+		{
+			name:     "fallback",
+			locale:   "fr",
+			key:      "messages.updated_profile",
+			selector: "female",
+			want:     "steve actualizó su perfil como autora",
+		},
+		{
+			name:     "missing",
+			locale:   "en",
+			key:      "messages.missing",
+			selector: "female",
+			want:     "messages.missing",
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := New(tt.locale, "es")
+			if got := t.GetSelect(tt.key, tt.selector, &Replacements{"user": "steve"}); got != tt.want {
+				t1.Errorf("GetSelect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizer_GetOrdinal(t1 *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		n      int
+		want   string
+	}{
+		{name: "one", locale: "en", n: 1, want: "1st place"},
+		{name: "two", locale: "en", n: 22, want: "22nd place"},
+		{name: "few", locale: "en", n: 103, want: "103rd place"},
+		{name: "teens", locale: "en", n: 12, want: "12th place"},
+		{name: "other", locale: "en", n: 4, want: "4th place"},
+		{name: "locale", locale: "es", n: 1, want: "1.º puesto"},
+		{name: "fallback", locale: "fr", n: 1, want: "1.º puesto"},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := New(tt.locale, "es")
+			if got := t.GetOrdinal("messages.ranked", tt.n); got != tt.want {
+				t1.Errorf("GetOrdinal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ordinalCategory(t *testing.T) {
+	tests := []struct {
+		locale string
+		n      int
+		want   string
+	}{
+		{locale: "en", n: 1, want: "one"},
+		{locale: "en-GB", n: 21, want: "one"},
+		{locale: "en", n: 11, want: "other"},
+		{locale: "en", n: 2, want: "two"},
+		{locale: "en", n: 13, want: "other"},
+		{locale: "en", n: 33, want: "few"},
+		{locale: "en", n: -1, want: "one"},
+		{locale: "fr", n: 1, want: "one"},
+		{locale: "fr", n: 2, want: "other"},
+		{locale: "it", n: 8, want: "many"},
+		{locale: "sv", n: 2, want: "one"},
+		{locale: "sv", n: 12, want: "other"},
+		{locale: "cy", n: 0, want: "zero"},
+		{locale: "cy", n: 5, want: "many"},
+		{locale: "ca", n: 4, want: "few"},
+		{locale: "az", n: 300, want: "few"},
+		{locale: "az", n: 1000, want: "other"},
+		{locale: "az", n: 6, want: "many"},
+		{locale: "ka", n: 40, want: "many"},
+		{locale: "es", n: 1, want: "other"},
+		{locale: "zz", n: 1, want: "other"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v %d", tt.locale, tt.n), func(t *testing.T) {
+			if got := ordinalCategory(tt.locale, tt.n); got != tt.want {
+				t.Errorf("ordinalCategory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizer_GetPlural(t1 *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		n      int
+		want   string
+	}{
+		{name: "exact", locale: "en", n: 0, want: "Your cart is empty"},
+		{name: "one", locale: "en", n: 1, want: "One item in your cart"},
+		{name: "other", locale: "en", n: 21, want: "21 items in your cart"},
+		{name: "locale", locale: "es", n: 1, want: "Un artículo en tu carrito"},
+		{name: "fallback", locale: "fr", n: 2, want: "2 artículos en tu carrito"},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := New(tt.locale, "es")
+			if got := t.GetPlural("app.cartItems", tt.n); got != tt.want {
+				t1.Errorf("GetPlural() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizer_GetPlural_android(t1 *testing.T) {
+	t := New("en", "es")
+	if got, want := t.GetPlural("strings.unread", 1), "1 unread message"; got != want {
+		t1.Errorf("GetPlural() = %v, want %v", got, want)
+	}
+	if got, want := t.GetPlural("strings.unread", 5), "5 unread messages"; got != want {
+		t1.Errorf("GetPlural() = %v, want %v", got, want)
+	}
+}
+
+func Test_cardinalCategory(t *testing.T) {
+	tests := []struct {
+		locale string
+		n      int
+		want   string
+	}{
+		{locale: "en", n: 1, want: "one"},
+		{locale: "en-GB", n: 0, want: "other"},
+		{locale: "en", n: 21, want: "other"},
+		{locale: "en", n: -1, want: "one"},
+		{locale: "fr", n: 0, want: "one"},
+		{locale: "fr", n: 2, want: "other"},
+		{locale: "fr", n: 1000000, want: "many"},
+		{locale: "es", n: 0, want: "other"},
+		{locale: "pt-BR", n: 0, want: "one"},
+		{locale: "pt_PT", n: 0, want: "other"},
+		{locale: "ru", n: 21, want: "one"},
+		{locale: "ru", n: 23, want: "few"},
+		{locale: "ru", n: 12, want: "many"},
+		{locale: "pl", n: 22, want: "few"},
+		{locale: "pl", n: 21, want: "many"},
+		{locale: "cs", n: 3, want: "few"},
+		{locale: "cs", n: 5, want: "other"},
+		{locale: "lt", n: 11, want: "other"},
+		{locale: "ar", n: 0, want: "zero"},
+		{locale: "ar", n: 2, want: "two"},
+		{locale: "ar", n: 105, want: "few"},
+		{locale: "ar", n: 111, want: "many"},
+		{locale: "ar", n: 100, want: "other"},
+		{locale: "ja", n: 1, want: "other"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v %d", tt.locale, tt.n), func(t *testing.T) {
+			if got := cardinalCategory(tt.locale, tt.n); got != tt.want {
+				t.Errorf("cardinalCategory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizer_pseudo(t1 *testing.T) {
+	t := New("en-XA", "en")
+	if got, want := t.Get("messages.hello_my_name_is", &Replacements{"name": "steve"}), "[Ĥéļļö ṁý ñåṁé îš steve~~~~~~]"; got != want {
+		t1.Errorf("Get() = %v, want %v", got, want)
+	}
+	if got, want := t.GetOrdinal("messages.ranked", 2), "[2ñð þļåçé~~~]"; got != want {
+		t1.Errorf("GetOrdinal() = %v, want %v", got, want)
+	}
+}
+
+func TestLocalizer_Direction(t1 *testing.T) {
+	tests := []struct {
+		locale string
+		want   Direction
+	}{
+		{locale: "en", want: LeftToRight},
+		{locale: "ar", want: RightToLeft},
+		{locale: "he-IL", want: RightToLeft},
+		{locale: "fa_IR", want: RightToLeft},
+		{locale: "az-Arab", want: RightToLeft},
+		{locale: "ku-Latn-IQ", want: LeftToRight},
+		{locale: "ur-Latn", want: LeftToRight},
+		{locale: "", want: LeftToRight},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.locale, func(t1 *testing.T) {
+			if got := New(tt.locale, "en").Direction(); got != tt.want {
+				t1.Errorf("Direction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizer_WithBidiIsolation(t1 *testing.T) {
+	overrides := map[string]string{
+		"ar.messages.sent":           "أرسلت إلى {{.email}}",
+		"ar.messages.sent_html":      "<b>أرسلت إلى {{.email}}</b>",
+		"ar.messages.order":          "الطلب %05d",
+		"ar.messages.padded":         "الطلب %*d %.*f %[6]*[5]s",
+		"en.messages.sent":           "Sent to {{.email}}",
+		"en.messages.order":          "Order %05d",
+		"ar.messages.sent_reference": `{{t "messages.sent"}}`,
+	}
+	tests := []struct {
+		name   string
+		locale string
+		get    func(t Localizer) string
+		want   string
+	}{
+		{
+			name:   "rtl",
+			locale: "ar",
+			get: func(t Localizer) string {
+				return t.Get("messages.sent", &Replacements{"email": "a@b.com"})
+			},
+			want: "أرسلت إلى \u2068a@b.com\u2069",
+		},
+		{
+			name:   "rtl reference",
+			locale: "ar",
+			get: func(t Localizer) string {
+				return t.Get("messages.sent_reference", &Replacements{"email": "a@b.com"})
+			},
+			want: "أرسلت إلى \u2068a@b.com\u2069",
+		},
+		{
+			name:   "rtl html",
+			locale: "ar",
+			get: func(t Localizer) string {
+				return string(t.GetHTML("messages.sent_html", &Replacements{"email": "<a@b.com>"}))
+			},
+			want: "<b>أرسلت إلى \u2068&lt;a@b.com&gt;\u2069</b>",
+		},
+		{
+			name:   "rtl getf",
+			locale: "ar",
+			get: func(t Localizer) string {
+				return t.Getf("messages.order", 42)
+			},
+			want: "الطلب \u206800042\u2069",
+		},
+		{
+			name:   "rtl getf width and precision",
+			locale: "ar",
+			get: func(t Localizer) string {
+				return t.Getf("messages.padded", 4, 42, 1, 1.25, "ab", 3)
+			},
+			want: "الطلب \u2068  42\u2069 \u20681.2\u2069 \u2068 ab\u2069",
+		},
+		{
+			name:   "ltr",
+			locale: "en",
+			get: func(t Localizer) string {
+				return t.Get("messages.sent", &Replacements{"email": "a@b.com"})
+			},
+			want: "Sent to a@b.com",
+		},
+		{
+			name:   "ltr getf",
+			locale: "en",
+			get: func(t Localizer) string {
+				return t.Getf("messages.order", 42)
+			},
+			want: "Order 00042",
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := New(tt.locale, "en").WithOverrides(overrides).WithBidiIsolation()
+			if got := tt.get(t); got != tt.want {
+				t1.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t := New("ar", "en").WithOverrides(overrides)
+	if got, want := t.Get("messages.sent", &Replacements{"email": "a@b.com"}), "أرسلت إلى a@b.com"; got != want {
+		t1.Errorf("Get() without isolation = %q, want %q", got, want)
+	}
+}
+
+func TestMetadata(t1 *testing.T) {
+	m, ok := Metadata["app.cartItems"]
+	if !ok {
+		t1.Fatal("Metadata has no app.cartItems")
+	}
+	want := KeyMetadata{
+		Description:  "Number of items in the shopping cart",
+		Selector:     "count",
+		Placeholders: map[string]PlaceholderMetadata{"count": {Type: "int"}},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t1.Errorf("Metadata[app.cartItems] = %v, want %v", m, want)
+	}
+
+	t := New("es", "en")
+	if got, want := t.GetSelect("app.cartItems", "0"), "Tu carrito está vacío"; got != want {
+		t1.Errorf("GetSelect() = %q, want %q", got, want)
+	}
+	if got, want := t.GetSelect("app.cartItems", "other", &Replacements{m.Selector: 3}), "3 artículos en tu carrito"; got != want {
+		t1.Errorf("GetSelect() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,130 @@
+package localizationstest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/m1/go-localize/examples/localizations"
+)
+
+func greet(t localizations.Translator, name string) string {
+	return t.Get("messages.hello_my_name_is", &localizations.Replacements{"name": name})
+}
+
+func TestTranslator(t *testing.T) {
+	fake := New(map[string]string{
+		"messages.hello_my_name_is":    "hi steve",
+		"es.messages.hello_my_name_is": "hola steve",
+	})
+
+	if got := greet(fake, "steve"); got != "hi steve" {
+		t.Errorf("Get() = %v, want %v", got, "hi steve")
+	}
+	if got := fake.GetWithLocale("es", "messages.hello_my_name_is"); got != "hola steve" {
+		t.Errorf("GetWithLocale() = %v, want %v", got, "hola steve")
+	}
+	if got := fake.GetWithLocale("fr", "messages.hello_my_name_is"); got != "hi steve" {
+		t.Errorf("GetWithLocale() = %v, want %v", got, "hi steve")
+	}
+	if got, ok := fake.Lookup("messages.missing"); got != "" || ok {
+		t.Errorf("Lookup() = %v, %v, want %v, %v", got, ok, "", false)
+	}
+
+	want := []string{
+		"messages.hello_my_name_is",
+		"messages.hello_my_name_is",
+		"messages.hello_my_name_is",
+		"messages.missing",
+	}
+	if got := fake.Keys(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+
+	fake.Reset()
+	if got := fake.Keys(); len(got) != 0 {
+		t.Errorf("Keys() after Reset() = %v, want none", got)
+	}
+}
+
+func TestTranslator_GetHTML(t *testing.T) {
+	fake := New(map[string]string{
+		"messages.markup":      "<b>hi</b>",
+		"messages.markup_html": "<b>hi</b>",
+	})
+
+	if got := fake.GetHTML("messages.markup"); got != "&lt;b&gt;hi&lt;/b&gt;" {
+		t.Errorf("GetHTML() = %v, want %v", got, "&lt;b&gt;hi&lt;/b&gt;")
+	}
+	if got := fake.GetHTML("messages.markup_html"); got != "<b>hi</b>" {
+		t.Errorf("GetHTML() = %v, want %v", got, "<b>hi</b>")
+	}
+}
+
+func TestTranslator_Getf(t *testing.T) {
+	fake := New(map[string]string{"messages.items": "%s has %d items"})
+
+	if got := fake.Getf("messages.items", "steve", 3); got != "steve has 3 items" {
+		t.Errorf("Getf() = %v, want %v", got, "steve has 3 items")
+	}
+	if got := fake.Getf("messages.missing", "steve"); got != "messages.missing" {
+		t.Errorf("Getf() = %v, want %v", got, "messages.missing")
+	}
+	if got, want := fake.Keys(), []string{"messages.items", "messages.missing"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+}
+
+func TestTranslator_GetSelect(t *testing.T) {
+	fake := New(map[string]string{
+		"messages.updated.female": "updated her profile",
+		"messages.updated.other":  "updated their profile",
+	})
+
+	if got := fake.GetSelect("messages.updated", "female"); got != "updated her profile" {
+		t.Errorf("GetSelect() = %v, want %v", got, "updated her profile")
+	}
+	if got := fake.GetSelect("messages.updated", "male"); got != "updated their profile" {
+		t.Errorf("GetSelect() = %v, want %v", got, "updated their profile")
+	}
+	if got := fake.GetSelect("messages.missing", "male"); got != "messages.missing" {
+		t.Errorf("GetSelect() = %v, want %v", got, "messages.missing")
+	}
+}
+
+func TestTranslator_GetOrdinal(t *testing.T) {
+	fake := New(map[string]string{
+		"messages.ranked.1":     "1st",
+		"messages.ranked.other": "nth",
+	})
+
+	if got := fake.GetOrdinal("messages.ranked", 1); got != "1st" {
+		t.Errorf("GetOrdinal() = %v, want %v", got, "1st")
+	}
+	if got := fake.GetOrdinal("messages.ranked", 2); got != "nth" {
+		t.Errorf("GetOrdinal() = %v, want %v", got, "nth")
+	}
+}
+
+func TestTranslator_GetPlural(t *testing.T) {
+	fake := New(map[string]string{
+		"app.cartItems.0":     "empty",
+		"app.cartItems.other": "items",
+	})
+
+	if got := fake.GetPlural("app.cartItems", 0); got != "empty" {
+		t.Errorf("GetPlural() = %v, want %v", got, "empty")
+	}
+	if got := fake.GetPlural("app.cartItems", 1); got != "items" {
+		t.Errorf("GetPlural() = %v, want %v", got, "items")
+	}
+}
+
+func TestTranslator_Direction(t *testing.T) {
+	if got := New(nil).Direction(); got != localizations.LeftToRight {
+		t.Errorf("Direction() = %v, want %v", got, localizations.LeftToRight)
+	}
+	fake := &Translator{Dir: localizations.RightToLeft}
+	if got := fake.Direction(); got != localizations.RightToLeft {
+		t.Errorf("Direction() = %v, want %v", got, localizations.RightToLeft)
+	}
+}
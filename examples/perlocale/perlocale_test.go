@@ -0,0 +1,64 @@
+package perlocale
+
+import (
+	"testing"
+)
+
+func TestLocalizer_Get(t1 *testing.T) {
+	type fields struct {
+		Locale         string
+		FallbackLocale string
+	}
+	type args struct {
+		key          string
+		replacements []*Replacements
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   string
+	}{
+		{
+			name:   "field",
+			fields: fields{Locale: "en", FallbackLocale: "es"},
+			args:   args{key: "messages.hello"},
+			want:   "hello",
+		},
+		{
+			name:   "other locale",
+			fields: fields{Locale: "es", FallbackLocale: "en"},
+			args:   args{key: "messages.hello"},
+			want:   "Hola",
+		},
+		{
+			name:   "fallback",
+			fields: fields{Locale: "en", FallbackLocale: "es"},
+			args:   args{key: "customer.messages.hello"},
+			want:   "hello customer!",
+		},
+		{
+			name:   "no key",
+			fields: fields{Locale: "en", FallbackLocale: "es"},
+			args:   args{key: "messages.hello2"},
+			want:   "messages.hello2",
+		},
+		{
+			name:   "valid replacements",
+			fields: fields{Locale: "en", FallbackLocale: "es"},
+			args: args{
+				key:          "messages.hello_my_name_is",
+				replacements: []*Replacements{{"name": "test"}},
+			},
+			want: "Hello my name is test",
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := New(tt.fields.Locale, tt.fields.FallbackLocale)
+			if got := t.Get(tt.args.key, tt.args.replacements...); got != tt.want {
+				t1.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
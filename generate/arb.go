@@ -0,0 +1,273 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/m1/go-localize/loader"
+)
+
+const (
+	// arbDefaultFile is the file localizations without a file segment in
+	// their key are written to.
+	arbDefaultFile = "app"
+	// arbPluralSelector and arbSelectSelector are the replacements plurals
+	// and selects are chosen by, without a selector in their metadata.
+	arbPluralSelector = "count"
+	arbSelectSelector = "selector"
+)
+
+// arbPluralOrder is the order of the plural categories in ARB plurals.
+var arbPluralOrder = map[string]int{"zero": 0, "one": 1, "two": 2, "few": 3, "many": 4, "other": 5}
+
+// arbMetadata is the @key metadata of an ARB message.
+type arbMetadata struct {
+	Description  string                    `json:"description,omitempty"`
+	Placeholders map[string]arbPlaceholder `json:"placeholders,omitempty"`
+}
+
+// arbPlaceholder is the metadata of a placeholder of an ARB message.
+type arbPlaceholder struct {
+	Type    string `json:"type,omitempty"`
+	Example string `json:"example,omitempty"`
+	Format  string `json:"format,omitempty"`
+}
+
+// arbMessage is a message of an ARB file, either a string or the cases of a
+// plural or select.
+type arbMessage struct {
+	key   string
+	value string
+	cases map[string]string
+}
+
+// writeARB writes the localizations of each locale to dir as Flutter ARB files
+// named after the first segment of their keys and the locale, e.g. app_es.arb
+// for the es.app keys, with the metadata of the keys as @key metadata and their
+// references to other keys resolved.
+func writeARB(dir string, localizations map[string]string, metadata map[string]loader.Metadata) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	for locale, l := range splitLocales(localizations) {
+		l, err := resolveReferences(l)
+		if err != nil {
+			return fmt.Errorf("%v: %w", locale, err)
+		}
+		files, err := arbFiles(l)
+		if err != nil {
+			return fmt.Errorf("%v: %w", locale, err)
+		}
+		for file, messages := range files {
+			arbLocale := strings.ReplaceAll(locale, "-", "_")
+			b, err := newARB(arbLocale, messages, metadata)
+			if err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(filepath.Join(dir, file+"_"+arbLocale+".arb"), b, 0600); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// arbFiles groups the localizations of a locale by file, the first segment of
+// their keys, into messages by name. Keys with an other case are plurals or
+// selects, e.g. items.one and items.other, and the segments of the rest of the
+// keys are joined in lower camel case, e.g. errors.not_found is
+// errorsNot_found.
+func arbFiles(localizations map[string]string) (map[string]map[string]*arbMessage, error) {
+	files := map[string]map[string]*arbMessage{}
+	for key, value := range localizations {
+		file, rest := arbDefaultFile, key
+		if parts := strings.SplitN(key, ".", 2); len(parts) == 2 {
+			file, rest = parts[0], parts[1]
+		}
+		c := ""
+		if i := strings.LastIndexByte(rest, '.'); i >= 0 {
+			_, isKey := localizations[key[:len(key)-len(rest)]+rest[:i]]
+			if _, ok := localizations[key[:len(key)-len(rest)]+rest[:i]+"."+loader.OtherCase]; ok && !isKey {
+				rest, c = rest[:i], rest[i+1:]
+			}
+		}
+
+		name := arbName(rest)
+		if files[file] == nil {
+			files[file] = map[string]*arbMessage{}
+		}
+		messageKey := strings.TrimSuffix(key, "."+c)
+		message, ok := files[file][name]
+		if !ok {
+			message = &arbMessage{key: messageKey}
+			files[file][name] = message
+		}
+		if message.key != messageKey {
+			return nil, fmt.Errorf("%w: %v and %v are %v", ErrARBConflict, message.key, messageKey, name)
+		}
+		if c == "" {
+			message.value = value
+			continue
+		}
+		if message.cases == nil {
+			message.cases = map[string]string{}
+		}
+		message.cases[c] = value
+	}
+	return files, nil
+}
+
+// arbName returns the ARB message name of the rest of a key after its file,
+// its segments joined in lower camel case.
+func arbName(rest string) string {
+	segments := strings.Split(rest, ".")
+	for i := 1; i < len(segments); i++ {
+		if segments[i] != "" {
+			segments[i] = strings.ToUpper(segments[i][:1]) + segments[i][1:]
+		}
+	}
+	return strings.Join(segments, "")
+}
+
+// newARB returns the ARB file of the messages of a locale.
+func newARB(locale string, messages map[string]*arbMessage, metadata map[string]loader.Metadata) ([]byte, error) {
+	names := make([]string, 0, len(messages))
+	for name := range messages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b := &bytes.Buffer{}
+	b.WriteString("{\n  \"@@locale\": ")
+	if err := writeJSON(b, locale, ""); err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		message := messages[name]
+		value, meta := arbValue(message, metadata[message.key])
+
+		b.WriteString(",\n  ")
+		if err := writeJSON(b, name, ""); err != nil {
+			return nil, err
+		}
+		b.WriteString(": ")
+		if err := writeJSON(b, value, ""); err != nil {
+			return nil, err
+		}
+		if meta.Description == "" && len(meta.Placeholders) == 0 {
+			continue
+		}
+		b.WriteString(",\n  ")
+		if err := writeJSON(b, "@"+name, ""); err != nil {
+			return nil, err
+		}
+		b.WriteString(": ")
+		if err := writeJSON(b, meta, "  "); err != nil {
+			return nil, err
+		}
+	}
+	b.WriteString("\n}\n")
+	return b.Bytes(), nil
+}
+
+// arbValue returns the ICU message of an ARB message and its metadata, with a
+// placeholder for every replacement it substitutes.
+func arbValue(message *arbMessage, m loader.Metadata) (string, arbMetadata) {
+	meta := arbMetadata{Description: m.Description, Placeholders: map[string]arbPlaceholder{}}
+	addPlaceholders := func(value string) {
+		for _, match := range placeholderRegexp.FindAllStringSubmatch(value, -1) {
+			p := m.Placeholders[match[1]]
+			meta.Placeholders[match[1]] = arbPlaceholder{Type: p.Type, Example: p.Example, Format: p.Format}
+		}
+	}
+	if message.cases == nil {
+		addPlaceholders(message.value)
+		return convertPlaceholders(message.value, PlaceholdersSingle), meta
+	}
+
+	cases := make([]string, 0, len(message.cases))
+	plural := true
+	for c, value := range message.cases {
+		cases = append(cases, c)
+		_, category := arbPluralOrder[c]
+		plural = plural && (category || isDigits(c))
+		addPlaceholders(value)
+	}
+	// Exact matches come before the plural categories, and other is last.
+	rank := func(c string) int {
+		if r, ok := arbPluralOrder[c]; ok {
+			return r
+		}
+		return -1
+	}
+	sort.Slice(cases, func(i, j int) bool {
+		a, b := cases[i], cases[j]
+		if plural && rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		if !plural && (a == loader.OtherCase || b == loader.OtherCase) {
+			return b == loader.OtherCase
+		}
+		return a < b
+	})
+
+	selector, kind, selectorType := m.Selector, "select", "String"
+	if plural {
+		kind, selectorType = "plural", "num"
+	}
+	if selector == "" {
+		selector = arbSelectSelector
+		if plural {
+			selector = arbPluralSelector
+		}
+	}
+	p := m.Placeholders[selector]
+	if p.Type == "" {
+		p.Type = selectorType
+	}
+	meta.Placeholders[selector] = arbPlaceholder{Type: p.Type, Example: p.Example, Format: p.Format}
+
+	b := &strings.Builder{}
+	b.WriteString("{" + selector + ", " + kind + ",")
+	for _, c := range cases {
+		value := convertPlaceholders(message.cases[c], PlaceholdersSingle)
+		if isDigits(c) && plural {
+			c = "=" + c
+		}
+		b.WriteString(" " + c + "{" + value + "}")
+	}
+	b.WriteString("}")
+	return b.String(), meta
+}
+
+// writeJSON writes v to b as JSON, indented by prefix if it is an object.
+func writeJSON(b *bytes.Buffer, v interface{}, prefix string) error {
+	var value []byte
+	var err error
+	if prefix == "" {
+		value, err = json.Marshal(v)
+	} else {
+		value, err = json.MarshalIndent(v, prefix, "  ")
+	}
+	if err != nil {
+		return err
+	}
+	b.Write(value)
+	return nil
+}
+
+func isDigits(str string) bool {
+	for i := 0; i < len(str); i++ {
+		if str[i] < '0' || str[i] > '9' {
+			return false
+		}
+	}
+	return str != ""
+}
@@ -0,0 +1,155 @@
+package generate
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/m1/go-localize/loader"
+)
+
+func Test_writeARB(t *testing.T) {
+	dir := "test_files/arb"
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	err := writeARB(dir, map[string]string{
+		"en.app.hello":          "Hello {{.name}}!",
+		"en.app.brand":          "Acme",
+		"en.app.errors.denied":  "Denied by {{t \"app.brand\"}}",
+		"en.app.items.0":        "No items",
+		"en.app.items.one":      "One item",
+		"en.app.items.other":    "{{.count}} items",
+		"en.app.updated.female": "{{.user}} updated her profile",
+		"en.app.updated.other":  "{{.user}} updated their profile",
+		"pt-BR.app.hello":       "Olá {{.name}}!",
+	}, map[string]loader.Metadata{
+		"app.hello": {
+			Description:  "Greeting",
+			Placeholders: map[string]loader.PlaceholderMetadata{"name": {Type: "String", Example: "Bob"}},
+		},
+		"app.items": {
+			Placeholders: map[string]loader.PlaceholderMetadata{"count": {Type: "int"}},
+		},
+		"app.updated": {Selector: "gender"},
+	})
+	if err != nil {
+		t.Fatalf("writeARB() error = %v", err)
+	}
+
+	want := map[string]string{
+		"app_en.arb": `{
+  "@@locale": "en",
+  "brand": "Acme",
+  "errorsDenied": "Denied by Acme",
+  "hello": "Hello {name}!",
+  "@hello": {
+    "description": "Greeting",
+    "placeholders": {
+      "name": {
+        "type": "String",
+        "example": "Bob"
+      }
+    }
+  },
+  "items": "{count, plural, =0{No items} one{One item} other{{count} items}}",
+  "@items": {
+    "placeholders": {
+      "count": {
+        "type": "int"
+      }
+    }
+  },
+  "updated": "{gender, select, female{{user} updated her profile} other{{user} updated their profile}}",
+  "@updated": {
+    "placeholders": {
+      "gender": {
+        "type": "String"
+      },
+      "user": {}
+    }
+  }
+}
+`,
+		"app_pt_BR.arb": `{
+  "@@locale": "pt_BR",
+  "hello": "Olá {name}!",
+  "@hello": {
+    "description": "Greeting",
+    "placeholders": {
+      "name": {
+        "type": "String",
+        "example": "Bob"
+      }
+    }
+  }
+}
+`,
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, file := range files {
+		b, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[file.Name()] = string(b)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("writeARB() got = %v, want %v", got, want)
+	}
+}
+
+func Test_writeARB_roundTrip(t *testing.T) {
+	dir := "test_files/arb_round_trip"
+	localizations := map[string]string{
+		"en.app.hello":       "Hello {{.name}}!",
+		"en.app.items.0":     "No items",
+		"en.app.items.other": "{{.count}} items",
+		"es.app.hello":       "¡Hola {{.name}}!",
+	}
+	metadata := map[string]loader.Metadata{
+		"app.hello": {
+			Description:  "Greeting",
+			Placeholders: map[string]loader.PlaceholderMetadata{"name": {Type: "String", Example: "Bob"}},
+		},
+		"app.items": {
+			Placeholders: map[string]loader.PlaceholderMetadata{"count": {Type: "int", Format: "compact"}},
+			Selector:     "count",
+		},
+	}
+	if err := writeARB(dir, localizations, metadata); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loader.Load(os.DirFS(dir), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, localizations) {
+		t.Errorf("round trip got = %v, want %v", got, localizations)
+	}
+	gotMetadata, err := loader.LoadMetadata(os.DirFS(dir), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotMetadata, metadata) {
+		t.Errorf("round trip metadata got = %v, want %v", gotMetadata, metadata)
+	}
+}
+
+func Test_arbFiles_conflict(t *testing.T) {
+	_, err := arbFiles(map[string]string{
+		"app.errors.auth": "a",
+		"app.errorsAuth":  "b",
+	})
+	if !errors.Is(err, ErrARBConflict) {
+		t.Errorf("arbFiles() error = %v, wantErr %v", err, ErrARBConflict)
+	}
+}
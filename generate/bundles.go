@@ -0,0 +1,120 @@
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// BundleFormat is the format of the JSON bundles.
+type BundleFormat string
+
+const (
+	// BundleFlat bundles are objects of localizations by key, e.g.
+	// {"messages.hello": "hello"}.
+	BundleFlat BundleFormat = "flat"
+	// BundleNested bundles nest objects by key segment, e.g.
+	// {"messages": {"hello": "hello"}}.
+	BundleNested BundleFormat = "nested"
+)
+
+// Placeholders is the syntax substitutions like {{.name}} are written in to
+// the JSON bundles.
+type Placeholders string
+
+const (
+	// PlaceholdersGo leaves substitutions as they are, e.g. {{.name}}.
+	PlaceholdersGo Placeholders = "go"
+	// PlaceholdersSingle converts substitutions to {name}, e.g. for ICU
+	// message format libraries.
+	PlaceholdersSingle Placeholders = "single"
+	// PlaceholdersDouble converts substitutions to {{name}}, e.g. for
+	// i18next.
+	PlaceholdersDouble Placeholders = "double"
+)
+
+// placeholderRegexp matches substitutions of a single replacement, e.g.
+// {{.name}}, including the white space trimmed by trim markers, e.g. the
+// space before {{- .name}}.
+var placeholderRegexp = regexp.MustCompile(`(?:\s*\{\{-\s+|\{\{\s*)\.([A-Za-z_][A-Za-z0-9_]*)(?:\s+-\}\}\s*|\s*\}\})`)
+
+// writeBundles writes the localizations of each locale to dir as a JSON
+// bundle named after the locale, e.g. en.json, with their references to other
+// keys resolved.
+func writeBundles(dir string, format BundleFormat, placeholders Placeholders, localizations map[string]string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	for locale, l := range splitLocales(localizations) {
+		l, err := resolveReferences(l)
+		if err != nil {
+			return fmt.Errorf("%v: %w", locale, err)
+		}
+		bundle, err := newBundle(format, placeholders, l)
+		if err != nil {
+			return fmt.Errorf("%v: %w", locale, err)
+		}
+		b, err := json.MarshalIndent(bundle, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, locale+".json"), append(b, '\n'), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newBundle returns the bundle of the localizations of a locale.
+func newBundle(format BundleFormat, placeholders Placeholders, localizations map[string]string) (map[string]interface{}, error) {
+	keys := make([]string, 0, len(localizations))
+	for key := range localizations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	bundle := map[string]interface{}{}
+	for _, key := range keys {
+		value := convertPlaceholders(localizations[key], placeholders)
+		if format == BundleFlat {
+			bundle[key] = value
+			continue
+		}
+
+		segments := strings.Split(key, ".")
+		object := bundle
+		for _, segment := range segments[:len(segments)-1] {
+			child, ok := object[segment]
+			if !ok {
+				child = map[string]interface{}{}
+				object[segment] = child
+			}
+			if object, ok = child.(map[string]interface{}); !ok {
+				return nil, fmt.Errorf("%w: %v", ErrBundleConflict, key)
+			}
+		}
+		if _, ok := object[segments[len(segments)-1]]; ok {
+			return nil, fmt.Errorf("%w: %v", ErrBundleConflict, key)
+		}
+		object[segments[len(segments)-1]] = value
+	}
+	return bundle, nil
+}
+
+// convertPlaceholders returns str with its substitutions of a single
+// replacement converted to the placeholders syntax.
+func convertPlaceholders(str string, placeholders Placeholders) string {
+	switch placeholders {
+	case PlaceholdersSingle:
+		return placeholderRegexp.ReplaceAllString(str, "{${1}}")
+	case PlaceholdersDouble:
+		return placeholderRegexp.ReplaceAllString(str, "{{${1}}}")
+	}
+	return str
+}
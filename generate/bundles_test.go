@@ -0,0 +1,168 @@
+package generate
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_writeBundles(t *testing.T) {
+	dir := "test_files/bundles"
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	err := writeBundles(dir, BundleNested, PlaceholdersSingle, map[string]string{
+		"en.messages.hello":    "hello {{.name}}",
+		"en.messages.bye":      "bye",
+		"en.messages.brand":    "Acme",
+		"en.messages.welcome":  `Welcome to {{t "messages.brand"}}, {{.name}}`,
+		"es.customer.messages": "hola",
+	})
+	if err != nil {
+		t.Fatalf("writeBundles() error = %v", err)
+	}
+
+	want := map[string]string{
+		"en.json": "{\n  \"messages\": {\n    \"brand\": \"Acme\",\n    \"bye\": \"bye\",\n    \"hello\": \"hello {name}\",\n    \"welcome\": \"Welcome to Acme, {name}\"\n  }\n}\n",
+		"es.json": "{\n  \"customer\": {\n    \"messages\": \"hola\"\n  }\n}\n",
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, file := range files {
+		b, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[file.Name()] = string(b)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("writeBundles() got = %v, want %v", got, want)
+	}
+}
+
+func Test_newBundle(t *testing.T) {
+	type args struct {
+		format        BundleFormat
+		placeholders  Placeholders
+		localizations map[string]string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    map[string]interface{}
+		wantErr error
+	}{
+		{
+			name: "flat",
+			args: args{
+				format:       BundleFlat,
+				placeholders: PlaceholdersGo,
+				localizations: map[string]string{
+					"messages.hello":         "hello {{.name}}",
+					"messages.updated.other": "updated",
+				},
+			},
+			want: map[string]interface{}{
+				"messages.hello":         "hello {{.name}}",
+				"messages.updated.other": "updated",
+			},
+		},
+		{
+			name: "nested",
+			args: args{
+				format:       BundleNested,
+				placeholders: PlaceholdersDouble,
+				localizations: map[string]string{
+					"messages.hello":         "hello {{.name}}",
+					"messages.updated.other": "updated",
+					"customer.messages.bye":  "bye",
+				},
+			},
+			want: map[string]interface{}{
+				"messages": map[string]interface{}{
+					"hello":   "hello {{name}}",
+					"updated": map[string]interface{}{"other": "updated"},
+				},
+				"customer": map[string]interface{}{
+					"messages": map[string]interface{}{"bye": "bye"},
+				},
+			},
+		},
+		{
+			name: "nested conflict",
+			args: args{
+				format: BundleNested,
+				localizations: map[string]string{
+					"messages.hello":       "hello",
+					"messages.hello.other": "hello",
+				},
+			},
+			wantErr: ErrBundleConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newBundle(tt.args.format, tt.args.placeholders, tt.args.localizations)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("newBundle() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newBundle() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_convertPlaceholders(t *testing.T) {
+	tests := []struct {
+		name         string
+		str          string
+		placeholders Placeholders
+		want         string
+	}{
+		{
+			name:         "go",
+			str:          "Hello {{.name}}",
+			placeholders: PlaceholdersGo,
+			want:         "Hello {{.name}}",
+		},
+		{
+			name:         "single",
+			str:          "Hello {{.firstname}} {{- .lastname -}}!",
+			placeholders: PlaceholdersSingle,
+			want:         "Hello {firstname}{lastname}!",
+		},
+		{
+			name:         "trim markers",
+			str:          "Hi {{- .name -}} !",
+			placeholders: PlaceholdersSingle,
+			want:         "Hi{name}!",
+		},
+		{
+			name:         "double",
+			str:          "Hello {{ .name }}",
+			placeholders: PlaceholdersDouble,
+			want:         "Hello {{name}}",
+		},
+		{
+			name:         "other actions are left",
+			str:          `{{t "brand.name"}} {{if .name}}{{.name}}{{end}}`,
+			placeholders: PlaceholdersSingle,
+			want:         `{{t "brand.name"}} {{if .name}}{name}{{end}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertPlaceholders(tt.str, tt.placeholders); got != tt.want {
+				t.Errorf("convertPlaceholders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,692 @@
+// Package generate generates the go-localize localizations package from
+// folders of translation files.
+package generate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/m1/go-localize/loader"
+)
+
+const (
+	generatedHeader = "// Code generated by go-localize; DO NOT EDIT."
+)
+
+// Mode is how the localizations are output in the generated package.
+type Mode string
+
+const (
+	// ModeMap outputs the localizations as a map literal in the generated
+	// file.
+	ModeMap Mode = "map"
+	// ModeEmbed outputs the localizations as per-locale data files next to the
+	// generated file, embedded using go:embed and decoded the first time each
+	// locale is used.
+	ModeEmbed Mode = "embed"
+	// ModeLocales outputs the localizations of each locale in their own
+	// generated file, registering themselves in an init.
+	ModeLocales Mode = "locales"
+)
+
+var (
+	// ErrNoInputs is returned when no input folders are given.
+	ErrNoInputs = errors.New("no input folders")
+	// ErrInvalidFilename is returned when the file name is not a plain .go
+	// file name.
+	ErrInvalidFilename = errors.New("file name must be a .go file name without a directory")
+	// ErrInvalidPackage is returned when the package name is not a valid Go
+	// identifier.
+	ErrInvalidPackage = errors.New("invalid package name")
+	// ErrPackageMismatched is returned when the package name does not match
+	// the package of the Go files already in the output folder.
+	ErrPackageMismatched = errors.New("package name does not match the package already in the output folder")
+	// ErrInvalidMode is returned when the mode is not one of the Mode
+	// constants.
+	ErrInvalidMode = errors.New("invalid mode")
+	// ErrWriterMode is returned when a Writer is given for a mode that outputs
+	// more than the generated file.
+	ErrWriterMode = errors.New("mode can not be written to a writer")
+	// ErrNoModule is returned when the import path of the output folder can
+	// not be worked out from a go.mod file.
+	ErrNoModule = errors.New("no go.mod found for the output folder, set the import path")
+	// ErrReferenceCycle is returned when localizations reference each other
+	// in a cycle.
+	ErrReferenceCycle = errors.New("localizations reference each other in a cycle")
+	// ErrUnresolvedReference is returned when a reference to another key
+	// can't be resolved for an output without references, e.g. the JSON
+	// bundles, because the locale doesn't have the key.
+	ErrUnresolvedReference = errors.New("reference can not be resolved")
+	// ErrVerbMismatch is returned when the locales of a key use different fmt
+	// verbs.
+	ErrVerbMismatch = errors.New("locales use different fmt verbs")
+	// ErrInvalidValidation is returned when a validation is unknown.
+	ErrInvalidValidation = errors.New("invalid validation")
+	// ErrNoBaseLocale is returned when a validation needs the base locale
+	// and it is not set.
+	ErrNoBaseLocale = errors.New("validation needs the base locale, which is not set")
+	// ErrMissingKeys is returned when a locale doesn't have every key of the
+	// base locale.
+	ErrMissingKeys = errors.New("locale is missing keys of the base locale")
+	// ErrInvalidBundleFormat is returned when the bundle format is unknown.
+	ErrInvalidBundleFormat = errors.New("invalid bundle format")
+	// ErrInvalidPlaceholders is returned when the bundle placeholder syntax is
+	// unknown.
+	ErrInvalidPlaceholders = errors.New("invalid bundle placeholders")
+	// ErrBundleConflict is returned when a key of a nested bundle is both a
+	// localization and an object of localizations.
+	ErrBundleConflict = errors.New("key is both a localization and an object in a nested bundle")
+	// ErrARBConflict is returned when different keys are the same ARB message
+	// name, e.g. errors.auth and errorsAuth.
+	ErrARBConflict = errors.New("keys are the same ARB message name")
+	// ErrTypingsWithoutBundles is returned when typings are written without
+	// a bundles folder.
+	ErrTypingsWithoutBundles = errors.New("typings are written to the bundles folder, which is not set")
+	// ErrNoPseudoBase is returned when the pseudo-localization base locale
+	// has no localizations.
+	ErrNoPseudoBase = errors.New("pseudo-localization base locale has no localizations")
+)
+
+// Options configures a generation run.
+type Options struct {
+	// Inputs are the folders of translation files, later folders overriding
+	// earlier ones key-by-key.
+	Inputs []string
+	// FS, if set, is the file system the inputs are read from, the inputs
+	// being slash separated paths in it. Defaults to the OS file system.
+	FS fs.FS
+	// Output is the folder the package is generated in.
+	Output string
+	// Writer, if set, is written the generated file instead of the file in
+	// the output folder.
+	Writer io.Writer
+	// Package is the package name, defaulting to the package already in the
+	// output folder or the output folder name.
+	Package string
+	// Filename is the generated file name, defaulting to the package name.
+	Filename string
+	// Mode is how the localizations are output, defaulting to ModeMap.
+	Mode Mode
+	// BuildTags guards each locale's file with the i18n_<locale> and
+	// i18n_all build tags in ModeLocales.
+	BuildTags bool
+	// Fake generates a <package>test package in the output folder with a
+	// Translator test double.
+	Fake bool
+	// ImportPath is the import path of the output folder, used by the fake's
+	// package. Defaults to the path worked out from the go.mod file.
+	ImportPath string
+	// BaseLocale is the locale the others are translated from, e.g. en,
+	// which ValidationMissingKeys checks them against. It is also the locale
+	// of translation files without one, e.g. in an Android values folder.
+	BaseLocale string
+	// Validations are the checks of the localizations that fail generation,
+	// e.g. ValidationVerbs. Reference cycles always fail generation.
+	Validations []Validation
+	// Pseudo is the base locale to pseudo-localize, e.g. en, adding the
+	// PseudoLocale to the localizations. Empty for no pseudo-localization.
+	Pseudo string
+	// PseudoLocale is the pseudo-localized locale, defaulting to en-XA.
+	PseudoLocale string
+	// PseudoPadding is the percentage pseudo-localizations are padded by,
+	// defaulting to 30. Use a negative value for no padding.
+	PseudoPadding int
+	// Bundles is the folder to also write per-locale JSON bundles of the
+	// localizations to, e.g. for a web frontend. Empty for no bundles.
+	Bundles string
+	// BundleFormat is the format of the bundles, defaulting to BundleFlat.
+	BundleFormat BundleFormat
+	// BundlePlaceholders is the syntax substitutions like {{.name}} are
+	// converted to in the bundles, defaulting to PlaceholdersGo.
+	BundlePlaceholders Placeholders
+	// Typings also writes TypeScript typings of every key and its
+	// placeholders to the Bundles folder, as localizations.d.ts.
+	Typings bool
+	// Android is the folder to also write the localizations to as Android
+	// string resources, e.g. values-es/strings.xml for the es.strings keys.
+	// Empty for no Android resources.
+	Android string
+	// Apple is the folder to also write the localizations to as Apple strings
+	// files, e.g. es.lproj/Localizable.strings for the es.Localizable keys.
+	// Empty for no Apple strings files.
+	Apple string
+	// ARB is the folder to also write the localizations to as Flutter ARB
+	// files, e.g. app_es.arb for the es.app keys, with the keys' metadata.
+	// Empty for no ARB files.
+	ARB string
+	// Logger, if set, is used to report the keys each input layer overrode.
+	Logger *log.Logger
+}
+
+// packageData is the data the package template is executed with.
+type packageData struct {
+	Timestamp     time.Time
+	Localizations map[string]string
+	Package       string
+	LineUp        func(string) string
+	Embed         bool
+	DataDir       string
+	Locales       bool
+	Metadata      map[string]loader.Metadata
+}
+
+// localeData is the data the locale template is executed with.
+type localeData struct {
+	Timestamp     time.Time
+	Localizations map[string]string
+	Package       string
+	LineUp        func(string) string
+	Locale        string
+	BuildTag      string
+}
+
+// fakeData is the data the fake template is executed with.
+type fakeData struct {
+	Timestamp  time.Time
+	Package    string
+	ImportPath string
+}
+
+// LayerOverrides reports which keys an input layer overrode from the layers
+// before it.
+type LayerOverrides struct {
+	Dir  string
+	Keys []string
+}
+
+// Run generates the localizations package.
+func Run(opts Options) error {
+	if len(opts.Inputs) == 0 {
+		return ErrNoInputs
+	}
+	if opts.Filename != "" && (filepath.Base(opts.Filename) != opts.Filename ||
+		filepath.Ext(opts.Filename) != ".go" || strings.HasSuffix(opts.Filename, "_test.go")) {
+		return fmt.Errorf("%w: %q", ErrInvalidFilename, opts.Filename)
+	}
+	switch opts.Mode {
+	case "":
+		opts.Mode = ModeMap
+	case ModeMap:
+	case ModeEmbed, ModeLocales:
+		if opts.Writer != nil {
+			return fmt.Errorf("%w: %q", ErrWriterMode, opts.Mode)
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidMode, opts.Mode)
+	}
+	if opts.Fake && opts.Writer != nil {
+		return fmt.Errorf("%w: fake", ErrWriterMode)
+	}
+	if opts.Bundles != "" && opts.Writer != nil {
+		return fmt.Errorf("%w: bundles", ErrWriterMode)
+	}
+	if opts.Android != "" && opts.Writer != nil {
+		return fmt.Errorf("%w: android", ErrWriterMode)
+	}
+	if opts.Apple != "" && opts.Writer != nil {
+		return fmt.Errorf("%w: apple", ErrWriterMode)
+	}
+	if opts.ARB != "" && opts.Writer != nil {
+		return fmt.Errorf("%w: arb", ErrWriterMode)
+	}
+	if err := checkValidations(opts.Validations, opts.BaseLocale); err != nil {
+		return err
+	}
+	if opts.Typings && opts.Bundles == "" {
+		return ErrTypingsWithoutBundles
+	}
+	switch opts.BundleFormat {
+	case "":
+		opts.BundleFormat = BundleFlat
+	case BundleFlat, BundleNested:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidBundleFormat, opts.BundleFormat)
+	}
+	switch opts.BundlePlaceholders {
+	case "":
+		opts.BundlePlaceholders = PlaceholdersGo
+	case PlaceholdersGo, PlaceholdersSingle, PlaceholdersDouble:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidPlaceholders, opts.BundlePlaceholders)
+	}
+
+	localizations, overrides, err := generateLayers(opts.FS, opts.Inputs, opts.BaseLocale)
+	if err != nil {
+		return err
+	}
+	metadata, err := generateMetadata(opts.FS, opts.Inputs, opts.BaseLocale)
+	if err != nil {
+		return err
+	}
+	if opts.Pseudo != "" {
+		if err := pseudoLocalize(localizations, opts.Pseudo, opts.PseudoLocale, opts.PseudoPadding); err != nil {
+			return err
+		}
+	}
+	if err := checkReferences(localizations); err != nil {
+		return err
+	}
+	if err := checkOtherCases(localizations); err != nil {
+		return err
+	}
+	if err := validate(localizations, opts.Validations, opts.BaseLocale); err != nil {
+		return err
+	}
+
+	if opts.Logger != nil {
+		for _, override := range overrides {
+			if len(override.Keys) > 0 {
+				opts.Logger.Printf("%v overrides %d keys: %v", override.Dir, len(override.Keys), strings.Join(override.Keys, ", "))
+			}
+		}
+	}
+
+	if err := generateFile(opts, localizations, metadata); err != nil {
+		return err
+	}
+	if opts.Bundles != "" {
+		if err := writeBundles(opts.Bundles, opts.BundleFormat, opts.BundlePlaceholders, localizations); err != nil {
+			return err
+		}
+	}
+	if opts.Typings {
+		if err := writeTypings(opts.Bundles, localizations); err != nil {
+			return err
+		}
+	}
+	if opts.Android != "" {
+		if err := writeAndroid(opts.Android, localizations); err != nil {
+			return err
+		}
+	}
+	if opts.Apple != "" {
+		if err := writeApple(opts.Apple, localizations); err != nil {
+			return err
+		}
+	}
+	if opts.ARB != "" {
+		return writeARB(opts.ARB, localizations, metadata)
+	}
+	return nil
+}
+
+// inputFS returns the file system and root within it to read the input dir
+// from. Without a file system, dir is read from the OS file system.
+func inputFS(fsys fs.FS, dir string) (fs.FS, string) {
+	if fsys == nil {
+		return os.DirFS(dir), "."
+	}
+	return fsys, path.Clean(dir)
+}
+
+// generateMetadata merges the metadata of the keys of every input directory in
+// order, later directories overriding earlier ones key-by-key.
+func generateMetadata(fsys fs.FS, dirs []string, baseLocale string) (map[string]loader.Metadata, error) {
+	metadata := map[string]loader.Metadata{}
+	for _, dir := range dirs {
+		layerFS, root := inputFS(fsys, dir)
+		layer, err := loader.Options{BaseLocale: baseLocale}.LoadMetadata(layerFS, root)
+		if err != nil {
+			return nil, err
+		}
+		for key, m := range layer {
+			metadata[key] = metadata[key].Merge(m)
+		}
+	}
+	return metadata, nil
+}
+
+// generateLayers merges the localizations of every input directory in order,
+// later directories overriding earlier ones key-by-key. Translation files
+// without a locale, e.g. in an Android values folder, are the base locale's.
+func generateLayers(fsys fs.FS, dirs []string, baseLocale string) (map[string]string, []LayerOverrides, error) {
+	localizations := map[string]string{}
+	var overrides []LayerOverrides
+	for i, dir := range dirs {
+		layerFS, root := inputFS(fsys, dir)
+		layer, err := loader.Options{BaseLocale: baseLocale}.Load(layerFS, root)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		override := LayerOverrides{Dir: dir}
+		for key, value := range layer {
+			if _, ok := localizations[key]; ok && i > 0 {
+				override.Keys = append(override.Keys, key)
+			}
+			localizations[key] = value
+		}
+		sort.Strings(override.Keys)
+		overrides = append(overrides, override)
+	}
+	return localizations, overrides, nil
+}
+
+func generateFile(opts Options, localizations map[string]string, metadata map[string]loader.Metadata) error {
+	pkg, err := resolvePackage(opts.Output, opts.Package)
+	if err != nil {
+		return err
+	}
+	filename := opts.Filename
+	if filename == "" {
+		filename = pkg + ".go"
+	}
+
+	data := packageData{
+		Timestamp:     time.Now(),
+		Localizations: localizations,
+		Package:       pkg,
+		Embed:         opts.Mode == ModeEmbed,
+		DataDir:       strings.TrimSuffix(filename, ".go") + "_data",
+		Locales:       opts.Mode == ModeLocales,
+		Metadata:      metadata,
+	}
+	if data.Locales {
+		data.Localizations = nil
+	}
+
+	if opts.Writer != nil {
+		return writePackage(opts.Writer, data)
+	}
+
+	err = os.MkdirAll(opts.Output, 0700)
+	if err != nil {
+		return err
+	}
+
+	if data.Embed {
+		if err := writeLocaleData(filepath.Join(opts.Output, data.DataDir), localizations); err != nil {
+			return err
+		}
+	}
+	if data.Locales {
+		if err := writeLocaleFiles(opts.Output, filename, pkg, opts.BuildTags, localizations); err != nil {
+			return err
+		}
+	}
+	if opts.Fake {
+		if err := writeFake(opts.Output, pkg, opts.ImportPath); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Create(filepath.Join(opts.Output, filename))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return writePackage(f, data)
+}
+
+// writeFake writes the <pkg>test package with the Translator test double to
+// dir.
+func writeFake(dir, pkg, importPath string) error {
+	if importPath == "" {
+		var err error
+		if importPath, err = resolveImportPath(dir); err != nil {
+			return err
+		}
+	}
+
+	fakeDir := filepath.Join(dir, pkg+"test")
+	if err := os.MkdirAll(fakeDir, 0700); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(fakeDir, pkg+"test.go"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return fakeTemplate.Execute(f, fakeData{
+		Timestamp:  time.Now(),
+		Package:    pkg,
+		ImportPath: importPath,
+	})
+}
+
+// resolveImportPath works out the import path of dir from the module path of
+// the nearest go.mod file.
+func resolveImportPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for modDir := abs; ; {
+		b, err := ioutil.ReadFile(filepath.Join(modDir, "go.mod"))
+		if err == nil {
+			module := modulePath(b)
+			if module == "" {
+				return "", fmt.Errorf("%w: %v has no module path", ErrNoModule, filepath.Join(modDir, "go.mod"))
+			}
+			rel, err := filepath.Rel(modDir, abs)
+			if err != nil {
+				return "", err
+			}
+			return path.Join(module, filepath.ToSlash(rel)), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		parent := filepath.Dir(modDir)
+		if parent == modDir {
+			return "", ErrNoModule
+		}
+		modDir = parent
+	}
+}
+
+// modulePath returns the module path of the go.mod file contents.
+func modulePath(mod []byte) string {
+	for _, line := range strings.Split(string(mod), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], "\"`")
+		}
+	}
+	return ""
+}
+
+// writeLocaleData writes a JSON file per locale to dir, of the localizations
+// of the locale keyed without the locale. Stale locale files are removed.
+func writeLocaleData(dir string, localizations map[string]string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	stale, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range stale {
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+
+	for locale, l := range splitLocales(localizations) {
+		b, err := json.Marshal(l)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, locale+".json"), b, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLocaleFiles writes a Go file per locale to dir, named after the
+// generated file, that registers the localizations of the locale. Stale locale
+// files are removed.
+func writeLocaleFiles(dir, filename, pkg string, buildTags bool, localizations map[string]string) error {
+	base := strings.TrimSuffix(filename, ".go")
+	stale, err := filepath.Glob(filepath.Join(dir, base+"_*.go"))
+	if err != nil {
+		return err
+	}
+	for _, file := range stale {
+		generated, err := isGenerated(file)
+		if err != nil {
+			return err
+		}
+		if generated {
+			if err := os.Remove(file); err != nil {
+				return err
+			}
+		}
+	}
+
+	for locale, l := range splitLocales(localizations) {
+		name := localeName(locale)
+		data := localeData{
+			Timestamp:     time.Now(),
+			Localizations: l,
+			Package:       pkg,
+			Locale:        locale,
+		}
+		if buildTags {
+			data.BuildTag = "i18n_" + name
+		}
+
+		f, err := os.Create(filepath.Join(dir, base+"_"+name+".go"))
+		if err != nil {
+			return err
+		}
+		data.LineUp = lineUp(l)
+		err = localeTemplate.Execute(f, data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitLocales splits the localizations by locale, the first key segment,
+// keyed without the locale.
+func splitLocales(localizations map[string]string) map[string]map[string]string {
+	locales := map[string]map[string]string{}
+	for key, value := range localizations {
+		parts := strings.SplitN(key, ".", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if locales[parts[0]] == nil {
+			locales[parts[0]] = map[string]string{}
+		}
+		locales[parts[0]][parts[1]] = value
+	}
+	return locales
+}
+
+// localeName returns locale usable in file names and build tags, e.g. en-GB
+// is en_gb.
+func localeName(locale string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(locale))
+}
+
+func writePackage(w io.Writer, data packageData) error {
+	data.LineUp = lineUp(data.Localizations)
+	return packageTemplate.Execute(w, data)
+}
+
+// lineUp returns the padding lining up the values of the localizations map
+// literal after each key.
+func lineUp(localizations map[string]string) func(string) string {
+	maxWidth := 0
+	for name := range localizations {
+		if len(name) > maxWidth {
+			maxWidth = len(name)
+		}
+	}
+
+	return func(name string) string {
+		return strings.Repeat(" ", maxWidth-len(name))
+	}
+}
+
+// resolvePackage validates the package name of the generated file, defaulting
+// it to the package of the other files already in dir, or the name of dir.
+func resolvePackage(dir, pkg string) (string, error) {
+	existing, err := existingPackage(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if pkg == "" {
+		pkg = existing
+	}
+	if pkg == "" {
+		pkg = filepath.Base(dir)
+	}
+
+	if !token.IsIdentifier(pkg) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPackage, pkg)
+	}
+	if existing != "" && existing != pkg {
+		return "", fmt.Errorf("%w: %q is not %q", ErrPackageMismatched, pkg, existing)
+	}
+	return pkg, nil
+}
+
+// existingPackage returns the package name of the Go files already in dir,
+// ignoring tests and files previously generated by go-localize.
+func existingPackage(dir string) (string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, name), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			return "", err
+		}
+		if len(f.Comments) > 0 && f.Comments[0].List[0].Text == generatedHeader {
+			continue
+		}
+		return f.Name.Name, nil
+	}
+	return "", nil
+}
+
+// isGenerated returns whether file was generated by go-localize.
+func isGenerated(file string) (bool, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return false, err
+	}
+	return len(f.Comments) > 0 && f.Comments[0].List[0].Text == generatedHeader, nil
+}
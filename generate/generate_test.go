@@ -0,0 +1,736 @@
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/m1/go-localize/loader"
+)
+
+func TestRun(t *testing.T) {
+	type args struct {
+		opts Options
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+	}{
+		{
+			name: "valid",
+			args: args{Options{
+				Inputs: []string{"../examples/localizations_src"},
+				Output: "test_files",
+			}},
+		},
+		{
+			name: "file system and writer",
+			args: args{Options{
+				Inputs:  []string{"localizations_src"},
+				FS:      os.DirFS("../examples"),
+				Package: "localizations",
+				Writer:  &bytes.Buffer{},
+			}},
+		},
+		{
+			name: "valid layers",
+			args: args{Options{
+				Inputs: []string{"../mock/layers/base", "../mock/layers/brand"},
+				Output: "test_files",
+			}},
+		},
+		{
+			name: "embed",
+			args: args{Options{
+				Inputs: []string{"../examples/localizations_src"},
+				Output: "test_files/embed",
+				Mode:   ModeEmbed,
+			}},
+		},
+		{
+			name: "locales",
+			args: args{Options{
+				Inputs:    []string{"../examples/localizations_src"},
+				Output:    "test_files/locales",
+				Mode:      ModeLocales,
+				BuildTags: true,
+			}},
+		},
+		{
+			name: "fake",
+			args: args{Options{
+				Inputs: []string{"../examples/localizations_src"},
+				Output: "test_files/fake",
+				Fake:   true,
+			}},
+		},
+		{
+			name: "reference cycle",
+			args: args{Options{
+				Inputs: []string{"../mock/references/cycle"},
+				Output: "test_files",
+			}},
+			wantErr: ErrReferenceCycle,
+		},
+		{
+			name: "verb mismatch",
+			args: args{Options{
+				Inputs:      []string{"../mock/verbs/mismatch"},
+				Output:      "test_files",
+				Validations: []Validation{ValidationVerbs},
+			}},
+			wantErr: ErrVerbMismatch,
+		},
+		{
+			name: "verb mismatch unchecked",
+			args: args{Options{
+				Inputs: []string{"../mock/verbs/mismatch"},
+				Output: "test_files",
+			}},
+		},
+		{
+			name: "missing keys",
+			args: args{Options{
+				Inputs:      []string{"../mock/missing"},
+				Output:      "test_files",
+				BaseLocale:  "en",
+				Validations: []Validation{ValidationVerbs, ValidationMissingKeys},
+			}},
+			wantErr: ErrMissingKeys,
+		},
+		{
+			name: "missing keys unchecked",
+			args: args{Options{
+				Inputs:     []string{"../mock/missing"},
+				Output:     "test_files",
+				BaseLocale: "en",
+			}},
+		},
+		{
+			name: "missing keys without base locale",
+			args: args{Options{
+				Inputs:      []string{"../mock/missing"},
+				Output:      "test_files",
+				Validations: []Validation{ValidationMissingKeys},
+			}},
+			wantErr: ErrNoBaseLocale,
+		},
+		{
+			name: "invalid validation",
+			args: args{Options{
+				Inputs:      []string{"../mock/missing"},
+				Output:      "test_files",
+				Validations: []Validation{"spelling"},
+			}},
+			wantErr: ErrInvalidValidation,
+		},
+		{
+			name: "select cases without other",
+			args: args{Options{
+				Inputs: []string{"../mock/selects/no_other"},
+				Output: "test_files",
+			}},
+			wantErr: loader.ErrNoOtherCase,
+		},
+		{
+			name: "pseudo",
+			args: args{Options{
+				Inputs:        []string{"../examples/localizations_src"},
+				Output:        "test_files",
+				Pseudo:        "en",
+				PseudoLocale:  "qps",
+				PseudoPadding: 50,
+			}},
+		},
+		{
+			name: "pseudo without base locale",
+			args: args{Options{
+				Inputs: []string{"../examples/localizations_src"},
+				Output: "test_files",
+				Pseudo: "fr",
+			}},
+			wantErr: ErrNoPseudoBase,
+		},
+		{
+			name: "bundles",
+			args: args{Options{
+				Inputs:             []string{"../examples/localizations_src"},
+				Output:             "test_files",
+				Bundles:            "test_files/bundles",
+				BundleFormat:       BundleNested,
+				BundlePlaceholders: PlaceholdersSingle,
+			}},
+		},
+		{
+			name: "typings",
+			args: args{Options{
+				Inputs:  []string{"../examples/localizations_src"},
+				Output:  "test_files",
+				Bundles: "test_files/bundles",
+				Typings: true,
+			}},
+		},
+		{
+			name: "typings without bundles",
+			args: args{Options{
+				Inputs:  []string{"../examples/localizations_src"},
+				Output:  "test_files",
+				Typings: true,
+			}},
+			wantErr: ErrTypingsWithoutBundles,
+		},
+		{
+			name: "invalid bundle format",
+			args: args{Options{
+				Inputs:       []string{"../examples/localizations_src"},
+				Output:       "test_files",
+				Bundles:      "test_files/bundles",
+				BundleFormat: "xml",
+			}},
+			wantErr: ErrInvalidBundleFormat,
+		},
+		{
+			name: "invalid bundle placeholders",
+			args: args{Options{
+				Inputs:             []string{"../examples/localizations_src"},
+				Output:             "test_files",
+				Bundles:            "test_files/bundles",
+				BundlePlaceholders: "icu",
+			}},
+			wantErr: ErrInvalidPlaceholders,
+		},
+		{
+			name: "bundles writer",
+			args: args{Options{
+				Inputs:  []string{"../mock/layers/base"},
+				Bundles: "test_files/bundles",
+				Writer:  &bytes.Buffer{},
+			}},
+			wantErr: ErrWriterMode,
+		},
+		{
+			name: "android and apple",
+			args: args{Options{
+				Inputs:  []string{"../examples/localizations_src"},
+				Output:  "test_files",
+				Android: "test_files/android",
+				Apple:   "test_files/apple",
+			}},
+		},
+		{
+			name: "arb",
+			args: args{Options{
+				Inputs: []string{"../examples/localizations_src"},
+				Output: "test_files",
+				ARB:    "test_files/arb",
+			}},
+		},
+		{
+			name: "arb writer",
+			args: args{Options{
+				Inputs: []string{"../mock/layers/base"},
+				ARB:    "test_files/arb",
+				Writer: &bytes.Buffer{},
+			}},
+			wantErr: ErrWriterMode,
+		},
+		{
+			name: "android writer",
+			args: args{Options{
+				Inputs:  []string{"../mock/layers/base"},
+				Android: "test_files/android",
+				Writer:  &bytes.Buffer{},
+			}},
+			wantErr: ErrWriterMode,
+		},
+		{
+			name:    "no inputs",
+			args:    args{Options{Output: "test_files"}},
+			wantErr: ErrNoInputs,
+		},
+		{
+			name: "invalid mode",
+			args: args{Options{
+				Inputs: []string{"../mock/layers/base"},
+				Output: "test_files",
+				Mode:   "other",
+			}},
+			wantErr: ErrInvalidMode,
+		},
+		{
+			name: "embed writer",
+			args: args{Options{
+				Inputs: []string{"../mock/layers/base"},
+				Output: "test_files",
+				Mode:   ModeEmbed,
+				Writer: &bytes.Buffer{},
+			}},
+			wantErr: ErrWriterMode,
+		},
+		{
+			name: "fake writer",
+			args: args{Options{
+				Inputs: []string{"../mock/layers/base"},
+				Fake:   true,
+				Writer: &bytes.Buffer{},
+			}},
+			wantErr: ErrWriterMode,
+		},
+		{
+			name: "invalid filename",
+			args: args{Options{
+				Inputs:   []string{"../mock/layers/base"},
+				Output:   "test_files",
+				Filename: "i18n/i18n_gen.go",
+			}},
+			wantErr: ErrInvalidFilename,
+		},
+		{
+			name: "invalid package",
+			args: args{Options{
+				Inputs:  []string{"../mock/layers/base"},
+				Output:  "test_files",
+				Package: "i18n-gen",
+			}},
+			wantErr: ErrInvalidPackage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Run(tt.args.opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRun_writer(t *testing.T) {
+	b := &bytes.Buffer{}
+	err := Run(Options{
+		Inputs: []string{"locales"},
+		FS: fstest.MapFS{
+			"locales/en/messages.json": {Data: []byte(`{"hello": "hello"}`)},
+		},
+		Output: "test_files/writer",
+		Writer: b,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !strings.Contains(b.String(), "package writer\n") {
+		t.Errorf("Run() package not written")
+	}
+	if !strings.Contains(b.String(), `"en.messages.hello": "hello"`) {
+		t.Errorf("Run() localizations not written")
+	}
+	if _, err := os.Stat("test_files/writer"); !os.IsNotExist(err) {
+		t.Errorf("Run() wrote to the output folder, error = %v", err)
+	}
+}
+
+func Test_generateLayers(t *testing.T) {
+	type args struct {
+		fsys fs.FS
+		dirs []string
+	}
+	tests := []struct {
+		name          string
+		args          args
+		want          map[string]string
+		wantOverrides []LayerOverrides
+		wantErr       bool
+	}{
+		{
+			name: "single layer",
+			args: args{
+				dirs: []string{"../mock/layers/base"},
+			},
+			want: map[string]string{
+				"en.messages.hello": "hello",
+				"en.messages.bye":   "bye",
+			},
+			wantOverrides: []LayerOverrides{
+				{Dir: "../mock/layers/base"},
+			},
+		},
+		{
+			name: "overriding layer",
+			args: args{
+				dirs: []string{"../mock/layers/base", "../mock/layers/brand"},
+			},
+			want: map[string]string{
+				"en.messages.hello": "hello from brand",
+				"en.messages.bye":   "bye",
+			},
+			wantOverrides: []LayerOverrides{
+				{Dir: "../mock/layers/base"},
+				{Dir: "../mock/layers/brand", Keys: []string{"en.messages.hello"}},
+			},
+		},
+		{
+			name: "file system",
+			args: args{
+				fsys: fstest.MapFS{
+					"base/en/messages.json":  {Data: []byte(`{"hello": "hello", "bye": "bye"}`)},
+					"brand/en/messages.yaml": {Data: []byte(`hello: hello from brand`)},
+				},
+				dirs: []string{"base", "./brand/"},
+			},
+			want: map[string]string{
+				"en.messages.hello": "hello from brand",
+				"en.messages.bye":   "bye",
+			},
+			wantOverrides: []LayerOverrides{
+				{Dir: "base"},
+				{Dir: "./brand/", Keys: []string{"en.messages.hello"}},
+			},
+		},
+		{
+			name: "invalid layer",
+			args: args{
+				dirs: []string{"../mock/layers/base", "../mock"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing layer",
+			args: args{
+				dirs: []string{"../mock/layers/missing"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotOverrides, err := generateLayers(tt.args.fsys, tt.args.dirs, "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generateLayers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generateLayers() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotOverrides, tt.wantOverrides) {
+				t.Errorf("generateLayers() gotOverrides = %v, want %v", gotOverrides, tt.wantOverrides)
+			}
+		})
+	}
+}
+
+func Test_generateFile(t *testing.T) {
+	type args struct {
+		opts         Options
+		translations map[string]string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			args: args{
+				opts:         Options{Output: "test_files"},
+				translations: map[string]string{"hello": "one"},
+			},
+		},
+		{
+			name: "package and filename",
+			args: args{
+				opts:         Options{Output: "test_files/i18n-gen", Package: "i18n", Filename: "localizations_gen.go"},
+				translations: map[string]string{"hello": "one"},
+			},
+		},
+		{
+			name: "embed",
+			args: args{
+				opts:         Options{Output: "test_files/embed", Mode: ModeEmbed},
+				translations: map[string]string{"en.hello": "one"},
+			},
+		},
+		{
+			name: "invalid dir",
+			args: args{
+				opts:         Options{Output: ""},
+				translations: map[string]string{"hello": "one"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid package",
+			args: args{
+				opts:         Options{Output: "test_files/i18n-gen"},
+				translations: map[string]string{"hello": "one"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := generateFile(tt.args.opts, tt.args.translations, nil); (err != nil) != tt.wantErr {
+				t.Errorf("generateFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_writeLocaleData(t *testing.T) {
+	dir := "test_files/locale_data"
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "fr.json"), []byte(`{}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := writeLocaleData(dir, map[string]string{
+		"en.messages.hello": "hello",
+		"en.messages.bye":   "bye",
+		"es.messages.hello": "hola",
+	})
+	if err != nil {
+		t.Fatalf("writeLocaleData() error = %v", err)
+	}
+
+	want := map[string]string{
+		"en.json": `{"messages.bye":"bye","messages.hello":"hello"}`,
+		"es.json": `{"messages.hello":"hola"}`,
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, file := range files {
+		b, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[file.Name()] = string(b)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("writeLocaleData() got = %v, want %v", got, want)
+	}
+}
+
+func Test_writeLocaleFiles(t *testing.T) {
+	dir := "test_files/locale_files"
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	stale := []byte(generatedHeader + "\n\npackage locale_files\n")
+	if err := ioutil.WriteFile(filepath.Join(dir, "locale_files_fr.go"), stale, 0600); err != nil {
+		t.Fatal(err)
+	}
+	handwritten := []byte("package locale_files\n")
+	if err := ioutil.WriteFile(filepath.Join(dir, "locale_files_helpers.go"), handwritten, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := writeLocaleFiles(dir, "locale_files.go", "locale_files", true, map[string]string{
+		"en.messages.hello":    "hello",
+		"en-GB.messages.hello": "hello",
+	})
+	if err != nil {
+		t.Fatalf("writeLocaleFiles() error = %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(dir, "locale_files_en.go"),
+		filepath.Join(dir, "locale_files_en_gb.go"),
+		filepath.Join(dir, "locale_files_helpers.go"),
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("writeLocaleFiles() files = %v, want %v", files, want)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "locale_files_en_gb.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"//go:build i18n_en_gb || i18n_all\n",
+		`registerLocale("en-GB", map[string]string{`,
+		`"messages.hello": "hello",`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("writeLocaleFiles() file missing %q", want)
+		}
+	}
+}
+
+func Test_localeName(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		want   string
+	}{
+		{
+			name:   "language",
+			locale: "en",
+			want:   "en",
+		},
+		{
+			name:   "region",
+			locale: "en-GB",
+			want:   "en_gb",
+		},
+		{
+			name:   "script",
+			locale: "zh-Hant_TW",
+			want:   "zh_hant_tw",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := localeName(tt.locale); got != tt.want {
+				t.Errorf("localeName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resolvePackage(t *testing.T) {
+	type args struct {
+		dir string
+		pkg string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr error
+	}{
+		{
+			name: "dir name",
+			args: args{dir: "test_files/localizations"},
+			want: "localizations",
+		},
+		{
+			name: "package",
+			args: args{dir: "test_files/i18n-gen", pkg: "i18n"},
+			want: "i18n",
+		},
+		{
+			name: "existing package",
+			args: args{dir: "../mock/pkg/existing"},
+			want: "existing",
+		},
+		{
+			name: "matches existing package",
+			args: args{dir: "../mock/pkg/existing", pkg: "existing"},
+			want: "existing",
+		},
+		{
+			name: "ignores generated files",
+			args: args{dir: "../mock/pkg/generated", pkg: "other"},
+			want: "other",
+		},
+		{
+			name:    "invalid package",
+			args:    args{dir: "test_files/i18n-gen"},
+			wantErr: ErrInvalidPackage,
+		},
+		{
+			name:    "keyword package",
+			args:    args{dir: "test_files", pkg: "func"},
+			wantErr: ErrInvalidPackage,
+		},
+		{
+			name:    "mismatched package",
+			args:    args{dir: "../mock/pkg/existing", pkg: "other"},
+			wantErr: ErrPackageMismatched,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolvePackage(tt.args.dir, tt.args.pkg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("resolvePackage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("resolvePackage() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resolveImportPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		dir     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "module root",
+			dir:  "..",
+			want: "github.com/m1/go-localize",
+		},
+		{
+			name: "nested",
+			dir:  "../examples/localizations",
+			want: "github.com/m1/go-localize/examples/localizations",
+		},
+		{
+			name: "missing folder",
+			dir:  "test_files/missing",
+			want: "github.com/m1/go-localize/generate/test_files/missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveImportPath(tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("resolveImportPath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("resolveImportPath() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_modulePath(t *testing.T) {
+	tests := []struct {
+		name string
+		mod  string
+		want string
+	}{
+		{
+			name: "module",
+			mod:  "module github.com/m1/go-localize\n\ngo 1.16\n",
+			want: "github.com/m1/go-localize",
+		},
+		{
+			name: "quoted",
+			mod:  "// comment\nmodule \"example.com/app\"\n",
+			want: "example.com/app",
+		},
+		{
+			name: "no module",
+			mod:  "go 1.16\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := modulePath([]byte(tt.mod)); got != tt.want {
+				t.Errorf("modulePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
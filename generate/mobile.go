@@ -0,0 +1,409 @@
+package generate
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// androidDefaultFile and appleDefaultFile are the files localizations
+	// without a file segment in their key are written to.
+	androidDefaultFile = "strings"
+	appleDefaultFile   = "Localizable"
+)
+
+// pluralCategories are the CLDR plural categories mobile plurals are keyed by.
+var pluralCategories = map[string]bool{
+	"zero":  true,
+	"one":   true,
+	"two":   true,
+	"few":   true,
+	"many":  true,
+	"other": true,
+}
+
+// mobileFile is the localizations of a locale written to one mobile
+// translation file, its strings by name and its plurals by name and category.
+// keys are the keys of the names, without the locale.
+type mobileFile struct {
+	strings map[string]string
+	plurals map[string]map[string]string
+	keys    map[string]string
+}
+
+// mobileFiles groups the localizations of a locale by file, the first segment
+// of their keys, the rest being their name. Keys whose last segments are
+// plural categories, including other, are grouped into plurals.
+func mobileFiles(localizations map[string]string, defaultFile string) map[string]*mobileFile {
+	cases := map[string][]string{}
+	for key := range localizations {
+		if i := strings.LastIndexByte(key, '.'); i >= 0 {
+			cases[key[:i]] = append(cases[key[:i]], key[i+1:])
+		}
+	}
+	plurals := map[string]bool{}
+	for base, categories := range cases {
+		if _, ok := localizations[base]; ok || !strings.Contains(base, ".") {
+			continue
+		}
+		plural := true
+		for _, category := range categories {
+			plural = plural && pluralCategories[category]
+		}
+		plurals[base] = plural && localizations[base+".other"] != ""
+	}
+
+	files := map[string]*mobileFile{}
+	file := func(key string) (*mobileFile, string) {
+		name, rest := defaultFile, key
+		if parts := strings.SplitN(key, ".", 2); len(parts) == 2 {
+			name, rest = parts[0], parts[1]
+		}
+		if files[name] == nil {
+			files[name] = &mobileFile{
+				strings: map[string]string{},
+				plurals: map[string]map[string]string{},
+				keys:    map[string]string{},
+			}
+		}
+		files[name].keys[rest] = key
+		return files[name], rest
+	}
+	for key, value := range localizations {
+		i := strings.LastIndexByte(key, '.')
+		if i < 0 || !plurals[key[:i]] {
+			f, name := file(key)
+			f.strings[name] = value
+			continue
+		}
+		f, name := file(key[:i])
+		if f.plurals[name] == nil {
+			f.plurals[name] = map[string]string{}
+		}
+		f.plurals[name][key[i+1:]] = value
+	}
+	return files
+}
+
+// mobileArgs numbers the placeholders of each key, without its locale, for
+// printf style placeholders, e.g. %1$s. Replacements named argN, as loaded
+// from mobile translation files, are argument N, other replacements being
+// numbered after them in name order. The cases of plurals share their
+// numbering.
+func mobileArgs(localizations map[string]string) map[string]map[string]int {
+	names := map[string]map[string]bool{}
+	for key, value := range localizations {
+		parts := strings.SplitN(key, ".", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key = mobileArgsKey(parts[1])
+		if names[key] == nil {
+			names[key] = map[string]bool{}
+		}
+		for _, match := range placeholderRegexp.FindAllStringSubmatch(value, -1) {
+			names[key][match[1]] = true
+		}
+	}
+
+	args := map[string]map[string]int{}
+	for key, keyNames := range names {
+		args[key] = map[string]int{}
+		var other []string
+		last := 0
+		for name := range keyNames {
+			if n, ok := argNumber(name); ok {
+				args[key][name] = n
+				if n > last {
+					last = n
+				}
+				continue
+			}
+			other = append(other, name)
+		}
+		sort.Strings(other)
+		for i, name := range other {
+			args[key][name] = last + i + 1
+		}
+	}
+	return args
+}
+
+// mobileArgsKey returns the key placeholders of key are numbered by, its
+// plural's key if it is a plural category case.
+func mobileArgsKey(key string) string {
+	if i := strings.LastIndexByte(key, '.'); i >= 0 && pluralCategories[key[i+1:]] {
+		return key[:i]
+	}
+	return key
+}
+
+// argNumber returns N of a replacement named argN.
+func argNumber(name string) (int, bool) {
+	if !strings.HasPrefix(name, "arg") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "arg"))
+	return n, err == nil && n > 0 && strconv.Itoa(n) == strings.TrimPrefix(name, "arg")
+}
+
+// printfPlaceholders converts substitutions like {{.name}} to printf style
+// placeholders of the argument numbered by args, using verb, e.g. %1$s, or d
+// for the integer replacement, e.g. a plural's count. When there are
+// substitutions, % is escaped as %%.
+func printfPlaceholders(str string, args map[string]int, verb, integer string) string {
+	if !placeholderRegexp.MatchString(str) {
+		return str
+	}
+	str = strings.ReplaceAll(str, "%", "%%")
+	return placeholderRegexp.ReplaceAllStringFunc(str, func(match string) string {
+		name := placeholderRegexp.FindStringSubmatch(match)[1]
+		if name == integer {
+			return "%" + strconv.Itoa(args[name]) + "$d"
+		}
+		return "%" + strconv.Itoa(args[name]) + "$" + verb
+	})
+}
+
+// pluralCount returns the replacement that is the count of a plural with the
+// args, count or otherwise arg1 as loaded from mobile translation files, and
+// the number of its argument. Plurals that don't substitute their count are
+// chosen by an argument after the others.
+func pluralCount(args map[string]int) (string, int) {
+	for _, name := range []string{"count", "arg1"} {
+		if n, ok := args[name]; ok {
+			return name, n
+		}
+	}
+	last := 0
+	for _, n := range args {
+		if n > last {
+			last = n
+		}
+	}
+	return "", last + 1
+}
+
+// writeAndroid writes the localizations of each locale to dir as Android
+// string resources, e.g. values-es/strings.xml for the es.strings keys, with
+// their references to other keys resolved.
+func writeAndroid(dir string, localizations map[string]string) error {
+	localizations, err := resolveLocaleReferences(localizations)
+	if err != nil {
+		return err
+	}
+	args := mobileArgs(localizations)
+	for locale, l := range splitLocales(localizations) {
+		localeDir := filepath.Join(dir, androidValuesDir(locale))
+		if err := os.MkdirAll(localeDir, 0700); err != nil {
+			return err
+		}
+
+		for file, f := range mobileFiles(l, androidDefaultFile) {
+			b := &strings.Builder{}
+			b.WriteString(xml.Header)
+			b.WriteString("<!-- " + strings.TrimPrefix(generatedHeader, "// ") + " -->\n")
+			b.WriteString("<resources>\n")
+			for _, name := range sortedKeys(f.strings) {
+				value := printfPlaceholders(f.strings[name], args[mobileArgsKey(f.keys[name])], "s", "")
+				b.WriteString("    <string name=\"" + xmlAttr(name) + "\">" + androidValue(value) + "</string>\n")
+			}
+			for _, name := range sortedPluralKeys(f.plurals) {
+				b.WriteString("    <plurals name=\"" + xmlAttr(name) + "\">\n")
+				for _, category := range sortedKeys(f.plurals[name]) {
+					value := printfPlaceholders(f.plurals[name][category], args[f.keys[name]], "s", "")
+					b.WriteString("        <item quantity=\"" + category + "\">" + androidValue(value) + "</item>\n")
+				}
+				b.WriteString("    </plurals>\n")
+			}
+			b.WriteString("</resources>\n")
+
+			if err := ioutil.WriteFile(filepath.Join(localeDir, file+".xml"), []byte(b.String()), 0600); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// androidValuesDir returns the resource folder of a locale, e.g. values-es,
+// values-pt-rBR or values-b+sr+Latn.
+func androidValuesDir(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(locale, "_", "-"), "-")
+	switch {
+	case len(parts) == 1:
+		return "values-" + parts[0]
+	case len(parts) == 2 && (len(parts[1]) == 2 || len(parts[1]) == 3 && parts[1][0] >= '0' && parts[1][0] <= '9'):
+		return "values-" + parts[0] + "-r" + strings.ToUpper(parts[1])
+	default:
+		return "values-b+" + strings.Join(parts, "+")
+	}
+}
+
+// androidTagRegexp matches the start, end and empty element tags of the
+// inline markup of Android strings, e.g. <b>, </b> or <a href="...">.
+var androidTagRegexp = regexp.MustCompile(`</?([A-Za-z][\w:.-]*)(?:\s+[\w:.-]+="[^"<]*")*\s*/?>`)
+
+// androidValue returns str as the content of an Android string resource, its
+// inline markup, e.g. <b>bold</b>, written as markup and its text escaped.
+// Strings whose tags aren't balanced, e.g. "<me>", are escaped as text.
+func androidValue(str string) string {
+	tags := androidTagRegexp.FindAllStringSubmatchIndex(str, -1)
+	var open []string
+	for _, tag := range tags {
+		name := str[tag[2]:tag[3]]
+		switch {
+		case str[tag[1]-2] == '/':
+		case str[tag[0]+1] != '/':
+			open = append(open, name)
+		case len(open) > 0 && open[len(open)-1] == name:
+			open = open[:len(open)-1]
+		default:
+			return xmlEscape(androidEscape(str))
+		}
+	}
+	if len(open) > 0 {
+		return xmlEscape(androidEscape(str))
+	}
+
+	b := &strings.Builder{}
+	last := 0
+	for _, tag := range tags {
+		b.WriteString(xmlEscape(androidEscapeText(str[last:tag[0]])))
+		b.WriteString(str[tag[0]:tag[1]])
+		last = tag[1]
+	}
+	b.WriteString(xmlEscape(androidEscapeText(str[last:])))
+	if strings.HasPrefix(str, "@") || strings.HasPrefix(str, "?") {
+		return `\` + b.String()
+	}
+	return b.String()
+}
+
+// androidEscape escapes the characters Android string resources treat
+// specially, e.g. quotes and a leading @.
+func androidEscape(str string) string {
+	str = androidEscapeText(str)
+	if strings.HasPrefix(str, "@") || strings.HasPrefix(str, "?") {
+		str = `\` + str
+	}
+	return str
+}
+
+// androidEscapeText escapes the quotes, backslashes and control characters of
+// text in an Android string resource.
+func androidEscapeText(str string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`'`, `\'`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\t", `\t`,
+	).Replace(str)
+}
+
+// writeApple writes the localizations of each locale to dir as Apple strings
+// files, e.g. es.lproj/Localizable.strings for the es.Localizable keys, and
+// their plurals as .stringsdict files, with their references to other keys
+// resolved.
+func writeApple(dir string, localizations map[string]string) error {
+	localizations, err := resolveLocaleReferences(localizations)
+	if err != nil {
+		return err
+	}
+	args := mobileArgs(localizations)
+	for locale, l := range splitLocales(localizations) {
+		localeDir := filepath.Join(dir, locale+".lproj")
+		if err := os.MkdirAll(localeDir, 0700); err != nil {
+			return err
+		}
+
+		for file, f := range mobileFiles(l, appleDefaultFile) {
+			b := &strings.Builder{}
+			b.WriteString(generatedHeader + "\n\n")
+			for _, name := range sortedKeys(f.strings) {
+				value := printfPlaceholders(f.strings[name], args[mobileArgsKey(f.keys[name])], "@", "")
+				b.WriteString(appleQuote(name) + " = " + appleQuote(value) + ";\n")
+			}
+			if err := ioutil.WriteFile(filepath.Join(localeDir, file+".strings"), []byte(b.String()), 0600); err != nil {
+				return err
+			}
+			if len(f.plurals) == 0 {
+				continue
+			}
+
+			b.Reset()
+			b.WriteString(xml.Header)
+			b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+			b.WriteString("<plist version=\"1.0\">\n<dict>\n")
+			for _, name := range sortedPluralKeys(f.plurals) {
+				// The count is formatted as the integer the value variable
+				// is declared as.
+				count, n := pluralCount(args[f.keys[name]])
+				format := "%#@value@"
+				if n != 1 {
+					format = "%" + strconv.Itoa(n) + "$#@value@"
+				}
+				b.WriteString("\t<key>" + xmlEscape(name) + "</key>\n\t<dict>\n")
+				b.WriteString("\t\t<key>NSStringLocalizedFormatKey</key>\n\t\t<string>" + format + "</string>\n")
+				b.WriteString("\t\t<key>value</key>\n\t\t<dict>\n")
+				b.WriteString("\t\t\t<key>NSStringFormatSpecTypeKey</key>\n\t\t\t<string>NSStringPluralRuleType</string>\n")
+				b.WriteString("\t\t\t<key>NSStringFormatValueTypeKey</key>\n\t\t\t<string>d</string>\n")
+				for _, category := range sortedKeys(f.plurals[name]) {
+					value := printfPlaceholders(f.plurals[name][category], args[f.keys[name]], "@", count)
+					b.WriteString("\t\t\t<key>" + category + "</key>\n\t\t\t<string>" + xmlEscape(value) + "</string>\n")
+				}
+				b.WriteString("\t\t</dict>\n\t</dict>\n")
+			}
+			b.WriteString("</dict>\n</plist>\n")
+			if err := ioutil.WriteFile(filepath.Join(localeDir, file+".stringsdict"), []byte(b.String()), 0600); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// appleQuote quotes str for a .strings file.
+func appleQuote(str string) string {
+	return `"` + strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\t", `\t`,
+	).Replace(str) + `"`
+}
+
+// xmlEscape escapes str for XML text.
+func xmlEscape(str string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(str)
+}
+
+// xmlAttr escapes str for a double quoted XML attribute value.
+func xmlAttr(str string) string {
+	return strings.ReplaceAll(xmlEscape(str), `"`, "&quot;")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedPluralKeys(m map[string]map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,225 @@
+package generate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/m1/go-localize/loader"
+)
+
+func Test_writeAndroid(t *testing.T) {
+	dir := "test_files/android"
+	err := writeAndroid(dir, map[string]string{
+		"en.strings.brand":       "Acme",
+		"en.strings.hello":       "Hello, {{.name}}! 100%",
+		"en.strings.welcome":     "Welcome to {{t \"strings.brand\"}}",
+		"en.strings.quote":       "Don't \"quote\" <me>",
+		"en.strings.styled":      "Don't <b>{{.name}}</b> & <a href=\"https://example.com/?a=1&amp;b=2\">go</a>",
+		"en.strings.items.one":   "{{.arg1}} item",
+		"en.strings.items.other": "{{.arg1}} items",
+		"pt-BR.strings.hello":    "Olá, {{.name}}!",
+	})
+	if err != nil {
+		t.Fatalf("writeAndroid() error = %v", err)
+	}
+
+	want := map[string]string{
+		"values-en/strings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<!-- Code generated by go-localize; DO NOT EDIT. -->
+<resources>
+    <string name="brand">Acme</string>
+    <string name="hello">Hello, %1$s! 100%%</string>
+    <string name="quote">Don\'t \"quote\" &lt;me&gt;</string>
+    <string name="styled">Don\'t <b>%1$s</b> &amp; <a href="https://example.com/?a=1&amp;b=2">go</a></string>
+    <string name="welcome">Welcome to Acme</string>
+    <plurals name="items">
+        <item quantity="one">%1$s item</item>
+        <item quantity="other">%1$s items</item>
+    </plurals>
+</resources>
+`,
+		"values-pt-rBR/strings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<!-- Code generated by go-localize; DO NOT EDIT. -->
+<resources>
+    <string name="hello">Olá, %1$s!</string>
+</resources>
+`,
+	}
+	for file, want := range want {
+		b, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != want {
+			t.Errorf("writeAndroid() %v got = %v, want %v", file, string(b), want)
+		}
+	}
+}
+
+func Test_writeApple(t *testing.T) {
+	dir := "test_files/apple"
+	err := writeApple(dir, map[string]string{
+		"en.Localizable.brand":       "Acme",
+		"en.Localizable.hello":       "Hello, {{.name}}!",
+		"en.Localizable.welcome":     "{{t \"Localizable.hello\"}} Welcome to {{t \"Localizable.brand\"}}",
+		"en.Localizable.quote":       "Say \"hi\"\nthen",
+		"en.Localizable.items.one":   "{{.arg1}} item",
+		"en.Localizable.items.other": "{{.arg1}} items",
+		"en.Localizable.files.one":   "{{.count}} file in {{.album}}",
+		"en.Localizable.files.other": "{{.count}} files in {{.album}}",
+	})
+	if err != nil {
+		t.Fatalf("writeApple() error = %v", err)
+	}
+
+	want := map[string]string{
+		"en.lproj/Localizable.strings": `// Code generated by go-localize; DO NOT EDIT.
+
+"brand" = "Acme";
+"hello" = "Hello, %1$@!";
+"quote" = "Say \"hi\"\nthen";
+"welcome" = "Hello, %1$@! Welcome to Acme";
+`,
+		"en.lproj/Localizable.stringsdict": `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>files</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%2$#@value@</string>
+		<key>value</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>d</string>
+			<key>one</key>
+			<string>%2$d file in %1$@</string>
+			<key>other</key>
+			<string>%2$d files in %1$@</string>
+		</dict>
+	</dict>
+	<key>items</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%#@value@</string>
+		<key>value</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>d</string>
+			<key>one</key>
+			<string>%1$d item</string>
+			<key>other</key>
+			<string>%1$d items</string>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`,
+	}
+	for file, want := range want {
+		b, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != want {
+			t.Errorf("writeApple() %v got = %v, want %v", file, string(b), want)
+		}
+	}
+}
+
+func Test_mobileRoundTrip(t *testing.T) {
+	localizations := map[string]string{
+		"en.messages.hello":       "Hello, {{.arg1}}! It's 100% \"done\"",
+		"en.messages.items.one":   "{{.arg1}} item in {{.arg2}}",
+		"en.messages.items.other": "{{.arg1}} items in {{.arg2}}",
+		"en.messages.multiline":   "first\nsecond",
+		"en.messages.styled":      "Hola <b>{{.arg1}}</b>, <i>\"bienvenido\"</i>",
+		"es.messages.hello":       "Hola, {{.arg1}}",
+	}
+	tests := []struct {
+		name  string
+		write func(string, map[string]string) error
+	}{
+		{name: "android", write: writeAndroid},
+		{name: "apple", write: writeApple},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join("test_files/round_trip", tt.name)
+			if err := tt.write(dir, localizations); err != nil {
+				t.Fatal(err)
+			}
+			got, err := loader.Load(os.DirFS(dir), ".")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, localizations) {
+				t.Errorf("round trip got = %v, want %v", got, localizations)
+			}
+		})
+	}
+}
+
+func Test_androidMarkupRoundTrip(t *testing.T) {
+	file := `<?xml version="1.0" encoding="UTF-8"?>
+<!-- Code generated by go-localize; DO NOT EDIT. -->
+<resources>
+    <string name="hello">Hola <b>%1$s</b>, <a href="https://example.com/?a=1&amp;b=2">mira</a> &amp; <i>ven</i></string>
+</resources>
+`
+	localizations, err := loader.Load(fstest.MapFS{"values-es/strings.xml": {Data: []byte(file)}}, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := "test_files/android_markup"
+	if err := writeAndroid(dir, localizations); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "values-es/strings.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != file {
+		t.Errorf("writeAndroid() got = %v, want %v", string(b), file)
+	}
+}
+
+func Test_mobileArgs(t *testing.T) {
+	got := mobileArgs(map[string]string{
+		"en.messages.hello":       "{{.user}} and {{.arg2}} and {{.count}}",
+		"es.messages.hello":       "{{.user}}",
+		"en.messages.items.one":   "{{.count}} item",
+		"en.messages.items.other": "{{.count}} items in {{.folder}}",
+	})
+	want := map[string]map[string]int{
+		"messages.hello": {"arg2": 2, "count": 3, "user": 4},
+		"messages.items": {"count": 1, "folder": 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mobileArgs() got = %v, want %v", got, want)
+	}
+}
+
+func Test_androidValuesDir(t *testing.T) {
+	tests := map[string]string{
+		"es":      "values-es",
+		"pt-BR":   "values-pt-rBR",
+		"es-419":  "values-es-r419",
+		"sr-Latn": "values-b+sr+Latn",
+	}
+	for locale, want := range tests {
+		t.Run(locale, func(t *testing.T) {
+			if got := androidValuesDir(locale); got != want {
+				t.Errorf("androidValuesDir() = %v, want %v", got, want)
+			}
+		})
+	}
+}
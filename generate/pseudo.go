@@ -0,0 +1,107 @@
+package generate
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultPseudoLocale is the pseudo-localized locale used by default.
+	DefaultPseudoLocale = "en-XA"
+	// DefaultPseudoPadding is the percentage pseudo-localizations are padded
+	// by when not set, roughly how much longer translations tend to be.
+	DefaultPseudoPadding = 30
+)
+
+// pseudoAccents are the accented replacements of ASCII letters.
+var pseudoAccents = map[rune]rune{
+	'A': 'Å', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Ð', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ',
+	'H': 'Ĥ', 'I': 'Î', 'J': 'Ĵ', 'K': 'Ķ', 'L': 'Ļ', 'M': 'Ṁ', 'N': 'Ñ',
+	'O': 'Ö', 'P': 'Þ', 'Q': 'Ǫ', 'R': 'Ŕ', 'S': 'Š', 'T': 'Ţ', 'U': 'Û',
+	'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ', 'Y': 'Ý', 'Z': 'Ž',
+	'a': 'å', 'b': 'ƀ', 'c': 'ç', 'd': 'ð', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ',
+	'h': 'ĥ', 'i': 'î', 'j': 'ĵ', 'k': 'ķ', 'l': 'ļ', 'm': 'ṁ', 'n': 'ñ',
+	'o': 'ö', 'p': 'þ', 'q': 'ǫ', 'r': 'ŕ', 's': 'š', 't': 'ţ', 'u': 'û',
+	'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ', 'y': 'ý', 'z': 'ž',
+}
+
+// pseudoLocalize adds the pseudo-localizations of the base locale's
+// localizations to localizations, as locale.
+func pseudoLocalize(localizations map[string]string, base, locale string, padding int) error {
+	if locale == "" {
+		locale = DefaultPseudoLocale
+	}
+	if padding == 0 {
+		padding = DefaultPseudoPadding
+	}
+
+	pseudo := map[string]string{}
+	for key, value := range splitLocales(localizations)[base] {
+		pseudo[locale+"."+key] = pseudoLocalization(value, padding, strings.HasSuffix(key, "_html"))
+	}
+	if len(pseudo) == 0 {
+		return fmt.Errorf("%w: %q", ErrNoPseudoBase, base)
+	}
+
+	for key, value := range pseudo {
+		localizations[key] = value
+	}
+	return nil
+}
+
+// pseudoLocalization returns str with its letters accented, padded by the
+// padding percentage of its length and wrapped in brackets, e.g. "Hello
+// {{.name}}" is "[Ĥéļļö {{.name}}~~~]". Substitutions, references and fmt
+// verbs are left intact, as are HTML tags when html is set.
+func pseudoLocalization(str string, padding int, html bool) string {
+	b := &strings.Builder{}
+	b.WriteString("[")
+
+	length := 0
+	for i := 0; i < len(str); {
+		if end := pseudoSkip(str[i:], html); end > 0 {
+			b.WriteString(str[i : i+end])
+			i += end
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(str[i:])
+		if accented, ok := pseudoAccents[r]; ok {
+			r = accented
+		}
+		b.WriteRune(r)
+		length++
+		i += size
+	}
+
+	if padding > 0 {
+		b.WriteString(strings.Repeat("~", (length*padding+99)/100))
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// pseudoSkip returns the length of the substitution, fmt verb or, when html
+// is set, HTML tag at the start of str that must be left intact, or 0.
+func pseudoSkip(str string, html bool) int {
+	switch {
+	case strings.HasPrefix(str, "{{"):
+		if end := strings.Index(str, "}}"); end >= 0 {
+			return end + len("}}")
+		}
+	case strings.HasPrefix(str, "%"):
+		if strings.HasPrefix(str, "%%") {
+			return len("%%")
+		}
+		if end := strings.IndexAny(str[1:], "vTtbcdoOUeEfFgGsqxXp"); end >= 0 &&
+			strings.Trim(str[1:end+1], "+-# 0123456789.*[]") == "" {
+			return end + 2
+		}
+	case html && strings.HasPrefix(str, "<"):
+		if end := strings.Index(str, ">"); end >= 0 {
+			return end + len(">")
+		}
+	}
+	return 0
+}
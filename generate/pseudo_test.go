@@ -0,0 +1,131 @@
+package generate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_pseudoLocalize(t *testing.T) {
+	type args struct {
+		localizations map[string]string
+		base          string
+		locale        string
+		padding       int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    map[string]string
+		wantErr error
+	}{
+		{
+			name: "defaults",
+			args: args{
+				localizations: map[string]string{
+					"en.messages.hello": "Hello",
+					"es.messages.hello": "Hola",
+				},
+				base: "en",
+			},
+			want: map[string]string{
+				"en.messages.hello":    "Hello",
+				"es.messages.hello":    "Hola",
+				"en-XA.messages.hello": "[Ĥéļļö~~]",
+			},
+		},
+		{
+			name: "locale and padding",
+			args: args{
+				localizations: map[string]string{
+					"en.messages.hello": "Hello",
+				},
+				base:    "en",
+				locale:  "qps",
+				padding: 100,
+			},
+			want: map[string]string{
+				"en.messages.hello":  "Hello",
+				"qps.messages.hello": "[Ĥéļļö~~~~~]",
+			},
+		},
+		{
+			name: "no base locale",
+			args: args{
+				localizations: map[string]string{
+					"es.messages.hello": "Hola",
+				},
+				base: "en",
+			},
+			wantErr: ErrNoPseudoBase,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := pseudoLocalize(tt.args.localizations, tt.args.base, tt.args.locale, tt.args.padding)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("pseudoLocalize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(tt.args.localizations, tt.want) {
+				t.Errorf("pseudoLocalize() got = %v, want %v", tt.args.localizations, tt.want)
+			}
+		})
+	}
+}
+
+func Test_pseudoLocalization(t *testing.T) {
+	type args struct {
+		str     string
+		padding int
+		html    bool
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "no padding",
+			args: args{str: "Hello", padding: -1},
+			want: "[Ĥéļļö]",
+		},
+		{
+			name: "substitutions",
+			args: args{str: "Hi {{.name}}, {{t \"brand.name\"}}", padding: -1},
+			want: "[Ĥî {{.name}}, {{t \"brand.name\"}}]",
+		},
+		{
+			name: "fmt verbs",
+			args: args{str: "%s has %[2]d items, 100%%", padding: -1},
+			want: "[%s ĥåš %[2]d îţéṁš, 100%%]",
+		},
+		{
+			name: "html",
+			args: args{str: "<b>Hi</b>", padding: -1, html: true},
+			want: "[<b>Ĥî</b>]",
+		},
+		{
+			name: "not html",
+			args: args{str: "<b>", padding: -1},
+			want: "[<ƀ>]",
+		},
+		{
+			name: "padding rounds up",
+			args: args{str: "Hi", padding: 30},
+			want: "[Ĥî~]",
+		},
+		{
+			name: "unclosed substitution",
+			args: args{str: "{{.name", padding: -1},
+			want: "[{{.ñåṁé]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pseudoLocalization(tt.args.str, tt.args.padding, tt.args.html); got != tt.want {
+				t.Errorf("pseudoLocalization() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
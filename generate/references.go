@@ -0,0 +1,264 @@
+package generate
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// referenceRegexp matches references to other keys, e.g. {{t "brand"}},
+// including the white space trimmed by trim markers.
+var referenceRegexp = regexp.MustCompile(`(?:\s*\{\{-\s+|\{\{\s*)t\s+("(?:[^"\\]|\\.)*"|\x60[^\x60]*\x60)(?:\s+-\}\}\s*|\s*\}\})`)
+
+// checkReferences returns ErrReferenceCycle if a localization references
+// itself using {{t "key"}}, directly or through other localizations. As at
+// runtime, references are resolved in the same locale, and references to keys
+// it doesn't have in the fallback locale, which can be any of the others, the
+// references of the fallback's localizations being resolved in the same
+// locale again.
+func checkReferences(localizations map[string]string) error {
+	locales := splitLocales(localizations)
+	names := make([]string, 0, len(locales))
+	for locale := range locales {
+		names = append(names, locale)
+	}
+	sort.Strings(names)
+
+	for _, locale := range names {
+		fallbacks := append([]string{""}, names...)
+		for _, fallback := range fallbacks {
+			if fallback == locale {
+				continue
+			}
+			cycle := referenceCycle(locales[locale], locales[fallback])
+			if cycle == nil {
+				continue
+			}
+			for i, key := range cycle {
+				if _, ok := locales[locale][key]; ok {
+					cycle[i] = locale + "." + key
+				} else {
+					cycle[i] = fallback + "." + key
+				}
+			}
+			return fmt.Errorf("%w: %v", ErrReferenceCycle, strings.Join(cycle, " -> "))
+		}
+	}
+	return nil
+}
+
+// referenceCycle returns the keys of the first reference cycle found in the
+// localizations of a locale, starting and ending with the same key, with the
+// keys the locale doesn't have resolved in the fallback localizations.
+func referenceCycle(localizations, fallback map[string]string) []string {
+	keys := make([]string, 0, len(localizations))
+	for key := range localizations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	value := func(key string) (string, bool) {
+		if str, ok := localizations[key]; ok {
+			return str, true
+		}
+		str, ok := fallback[key]
+		return str, ok
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := map[string]int{}
+	var path []string
+	var visit func(key string) []string
+	visit = func(key string) []string {
+		switch state[key] {
+		case visiting:
+			for i, k := range path {
+				if k == key {
+					return append(append([]string(nil), path[i:]...), key)
+				}
+			}
+		case visited:
+			return nil
+		}
+
+		state[key] = visiting
+		path = append(path, key)
+		str, _ := value(key)
+		for _, ref := range references(str) {
+			if _, ok := value(ref); !ok {
+				continue
+			}
+			if cycle := visit(ref); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[key] = visited
+		return nil
+	}
+
+	for _, key := range keys {
+		if cycle := visit(key); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// resolveLocaleReferences returns the localizations of every locale, keyed
+// by locale, with their references to other keys resolved by
+// resolveReferences.
+func resolveLocaleReferences(localizations map[string]string) (map[string]string, error) {
+	resolved := map[string]string{}
+	for locale, l := range splitLocales(localizations) {
+		l, err := resolveReferences(l)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", locale, err)
+		}
+		for key, value := range l {
+			resolved[locale+"."+key] = value
+		}
+	}
+	return resolved, nil
+}
+
+// resolveReferences returns the localizations of a locale with their
+// references to other keys replaced by the localizations of the keys, for
+// outputs other than the generated package, which have no {{t "key"}}. It
+// returns ErrUnresolvedReference for references to keys the locale doesn't
+// have, or that aren't a {{t "key"}} action of their own.
+func resolveReferences(localizations map[string]string) (map[string]string, error) {
+	resolved := map[string]string{}
+	var resolve func(key string, depth int) (string, error)
+	resolve = func(key string, depth int) (string, error) {
+		if str, ok := resolved[key]; ok {
+			return str, nil
+		}
+		str := localizations[key]
+		if !strings.Contains(str, "}}") {
+			return str, nil
+		}
+		if depth > len(localizations) {
+			return "", fmt.Errorf("%w: %v", ErrReferenceCycle, key)
+		}
+
+		var err error
+		str = referenceRegexp.ReplaceAllStringFunc(str, func(match string) string {
+			quoted := referenceRegexp.FindStringSubmatch(match)[1]
+			ref, unquoteErr := strconv.Unquote(quoted)
+			if _, ok := localizations[ref]; unquoteErr != nil || !ok {
+				if err == nil {
+					err = fmt.Errorf("%w: %v references %v", ErrUnresolvedReference, key, quoted)
+				}
+				return match
+			}
+			value, resolveErr := resolve(ref, depth+1)
+			if resolveErr != nil && err == nil {
+				err = resolveErr
+			}
+			return value
+		})
+		if err != nil {
+			return "", err
+		}
+		if refs := references(str); len(refs) > 0 {
+			return "", fmt.Errorf("%w: %v references %v", ErrUnresolvedReference, key, refs[0])
+		}
+		resolved[key] = str
+		return str, nil
+	}
+
+	for key := range localizations {
+		if _, err := resolve(key, 0); err != nil {
+			return nil, err
+		}
+	}
+	for key, str := range localizations {
+		if _, ok := resolved[key]; !ok {
+			resolved[key] = str
+		}
+	}
+	return resolved, nil
+}
+
+// references returns the keys referenced by str using {{t "key"}}.
+func references(str string) []string {
+	var keys []string
+	walkTemplate(str, func(node parse.Node) {
+		cmd, ok := node.(*parse.CommandNode)
+		if !ok || len(cmd.Args) != 2 {
+			return
+		}
+		ident, isIdent := cmd.Args[0].(*parse.IdentifierNode)
+		key, isString := cmd.Args[1].(*parse.StringNode)
+		if isIdent && isString && ident.Ident == "t" {
+			keys = append(keys, key.Text)
+		}
+	})
+	return keys
+}
+
+// walkTemplate calls fn for every node of str parsed as a template, the same
+// way the generated package parses it. Strings that aren't valid templates
+// have no nodes, as they are never executed.
+func walkTemplate(str string, fn func(parse.Node)) {
+	if !strings.Contains(str, "}}") {
+		return
+	}
+	tmpl, err := template.New("").Funcs(template.FuncMap{"t": func(string) string { return "" }}).Parse(str)
+	if err != nil || tmpl.Tree == nil {
+		return
+	}
+
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch node := node.(type) {
+		case *parse.ListNode:
+			if node == nil {
+				return
+			}
+			for _, n := range node.Nodes {
+				walk(n)
+			}
+			return
+		case *parse.PipeNode:
+			if node == nil {
+				return
+			}
+		}
+
+		fn(node)
+		switch node := node.(type) {
+		case *parse.ActionNode:
+			walk(node.Pipe)
+		case *parse.PipeNode:
+			for _, cmd := range node.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range node.Args {
+				walk(arg)
+			}
+		case *parse.IfNode:
+			walk(node.Pipe)
+			walk(node.List)
+			walk(node.ElseList)
+		case *parse.RangeNode:
+			walk(node.Pipe)
+			walk(node.List)
+			walk(node.ElseList)
+		case *parse.WithNode:
+			walk(node.Pipe)
+			walk(node.List)
+			walk(node.ElseList)
+		}
+	}
+	walk(tmpl.Tree.Root)
+}
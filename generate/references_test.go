@@ -0,0 +1,167 @@
+package generate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_checkReferences(t *testing.T) {
+	tests := []struct {
+		name          string
+		localizations map[string]string
+		wantErr       error
+		wantMsg       string
+	}{
+		{
+			name: "no references",
+			localizations: map[string]string{
+				"en.messages.hello": "hello {{.name}}",
+			},
+		},
+		{
+			name: "references",
+			localizations: map[string]string{
+				"en.brand.name":      "Acme",
+				"en.brand.welcome":   `Welcome to {{t "brand.name"}}`,
+				"en.messages.hello":  `{{t "brand.welcome"}}, {{.name}}`,
+				"es.messages.hello":  `{{t "brand.welcome"}}`,
+				"es.messages.unused": `{{t "messages.hello"}}`,
+			},
+		},
+		{
+			name: "cycle",
+			localizations: map[string]string{
+				"en.messages.a": `{{t "messages.b"}}`,
+				"en.messages.b": `{{if .name}}{{t "messages.c"}}{{end}}`,
+				"en.messages.c": `{{t "messages.a" | printf "%v"}}`,
+			},
+			wantErr: ErrReferenceCycle,
+			wantMsg: "localizations reference each other in a cycle: en.messages.a -> en.messages.b -> en.messages.c -> en.messages.a",
+		},
+		{
+			name: "self reference",
+			localizations: map[string]string{
+				"en.messages.a": "a",
+				"es.messages.a": `{{t "messages.a"}}`,
+			},
+			wantErr: ErrReferenceCycle,
+			wantMsg: "localizations reference each other in a cycle: es.messages.a -> es.messages.a",
+		},
+		{
+			name: "cycle through the fallback locale",
+			localizations: map[string]string{
+				"en.m.a": "A",
+				"en.m.b": `B {{t "m.a"}}`,
+				"es.m.a": `X {{t "m.b"}}`,
+			},
+			wantErr: ErrReferenceCycle,
+			wantMsg: "localizations reference each other in a cycle: es.m.a -> en.m.b -> es.m.a",
+		},
+		{
+			name: "references through the fallback locale",
+			localizations: map[string]string{
+				"en.m.a": "A",
+				"en.m.b": `B {{t "m.a"}}`,
+				"es.m.c": `X {{t "m.b"}}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReferences(tt.localizations)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkReferences() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && err.Error() != tt.wantMsg {
+				t.Errorf("checkReferences() error = %v, want %v", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func Test_references(t *testing.T) {
+	tests := []struct {
+		name string
+		str  string
+		want []string
+	}{
+		{
+			name: "none",
+			str:  "hello",
+		},
+		{
+			name: "replacements",
+			str:  "hello {{.name}}",
+		},
+		{
+			name: "references",
+			str:  `{{t "brand.name"}} {{with .name}}{{t "messages.name"}}{{else}}{{t "messages.anonymous"}}{{end}}`,
+			want: []string{"brand.name", "messages.name", "messages.anonymous"},
+		},
+		{
+			name: "invalid template",
+			str:  `{{t "brand.name"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := references(tt.str); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("references() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resolveReferences(t *testing.T) {
+	tests := []struct {
+		name          string
+		localizations map[string]string
+		want          map[string]string
+		wantErr       error
+	}{
+		{
+			name: "references",
+			localizations: map[string]string{
+				"brand":   "Acme",
+				"title":   "{{t `brand`}} Store",
+				"welcome": `Welcome to {{t "title"}}, {{.name}}`,
+				"trimmed": `Welcome   {{- t "brand" -}}  !`,
+			},
+			want: map[string]string{
+				"brand":   "Acme",
+				"title":   "Acme Store",
+				"welcome": "Welcome to Acme Store, {{.name}}",
+				"trimmed": "WelcomeAcme!",
+			},
+		},
+		{
+			name: "missing key",
+			localizations: map[string]string{
+				"welcome": `Welcome to {{t "brand"}}`,
+			},
+			wantErr: ErrUnresolvedReference,
+		},
+		{
+			name: "piped reference",
+			localizations: map[string]string{
+				"brand":   "Acme",
+				"welcome": `Welcome to {{t "brand" | printf "%q"}}`,
+			},
+			wantErr: ErrUnresolvedReference,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveReferences(tt.localizations)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("resolveReferences() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveReferences() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
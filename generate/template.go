@@ -0,0 +1,1302 @@
+package generate
+
+import (
+	"text/template"
+)
+
+var packageTemplate = template.Must(template.New("").Parse(generatedHeader + `
+// This file was generated by robots at
+// {{ .Timestamp }}
+
+package {{ .Package }}
+
+import (
+	"bytes"
+{{- if .Embed }}
+	"embed"
+	"encoding/json"
+{{- end }}
+	"fmt"
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+{{- if .Embed }}
+	"sync"
+{{- end }}
+	"text/template"
+	"text/template/parse"
+)
+{{ if .Embed }}
+//go:embed {{ .DataDir }}
+var localizationsData embed.FS
+
+var localizations = map[string]string{}
+
+var (
+	localesMu sync.RWMutex
+	locales   = map[string]map[string]string{}
+)
+
+// loadLocale returns the embedded localizations of locale, decoding them the
+// first time the locale is requested. Only locales with localizations are
+// kept, as locales often come from requests, e.g. Accept-Language headers.
+func loadLocale(locale string) map[string]string {
+	localesMu.RLock()
+	l, ok := locales[locale]
+	localesMu.RUnlock()
+	if ok {
+		return l
+	}
+
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	if l, ok := locales[locale]; ok {
+		return l
+	}
+
+	b, err := localizationsData.ReadFile("{{ .DataDir }}/" + locale + ".json")
+	if err != nil {
+		return nil
+	}
+	l = map[string]string{}
+	if err := json.Unmarshal(b, &l); err != nil {
+		panic(fmt.Sprintf("decoding the embedded localizations of %v: %v", locale, err))
+	}
+	locales[locale] = l
+	return l
+}
+{{- else if .Locales }}
+var localizations = map[string]string{}
+
+// registerLocale adds the localizations of locale, keyed without the locale.
+func registerLocale(locale string, l map[string]string) {
+	for key, value := range l {
+		localizations[locale+"."+key] = value
+	}
+}
+{{- else }}
+var localizations = map[string]string{
+{{- range $key, $element := .Localizations  }}
+	{{ printf "%q" $key }}:{{ call $.LineUp $key }} {{ printf "%q" $element }},
+{{- end }}
+}
+{{- end }}
+{{- if .Metadata }}
+
+// KeyMetadata describes a key, from the metadata of its translation files,
+// e.g. the @key blocks of ARB files.
+type KeyMetadata struct {
+	// Description is what the key is for, e.g. for translators.
+	Description string
+	// Selector is the replacement the select cases of the key are chosen by,
+	// e.g. count, for GetSelect, which GetPlural sets to its count.
+	Selector string
+	// Placeholders are the replacements the key substitutes, by name.
+	Placeholders map[string]PlaceholderMetadata
+}
+
+// PlaceholderMetadata describes a replacement of a key.
+type PlaceholderMetadata struct {
+	// Type is the type of the replacement, e.g. String, int or DateTime.
+	Type string
+	// Example is an example value of the replacement.
+	Example string
+	// Format is how the replacement is formatted, e.g. compact or yMd.
+	Format string
+}
+
+// Metadata is the metadata of the keys that have any, keyed without the
+// locale, e.g. app.hello.
+var Metadata = map[string]KeyMetadata{
+{{- range $key, $m := .Metadata }}
+	{{ printf "%q" $key }}: {
+		Description: {{ printf "%q" $m.Description }},
+		Selector:    {{ printf "%q" $m.Selector }},
+{{- if $m.Placeholders }}
+		Placeholders: map[string]PlaceholderMetadata{
+{{- range $name, $p := $m.Placeholders }}
+			{{ printf "%q" $name }}: {
+				Type:    {{ printf "%q" $p.Type }},
+				Example: {{ printf "%q" $p.Example }},
+				Format:  {{ printf "%q" $p.Format }},
+			},
+{{- end }}
+		},
+{{- end }}
+	},
+{{- end }}
+}
+
+// pluralSelector returns the replacement GetPlural sets to its count besides
+// n, the selector of the key's metadata, e.g. count, or otherwise arg1.
+func pluralSelector(key string) string {
+	if m, ok := Metadata[key]; ok && m.Selector != "" {
+		return m.Selector
+	}
+	return "arg1"
+}
+{{- else }}
+
+// pluralSelector returns the replacement GetPlural sets to its count besides
+// n, arg1 as there is no metadata.
+func pluralSelector(key string) string {
+	return "arg1"
+}
+{{- end }}
+
+type Replacements map[string]interface{}
+
+// Source provides localizations keyed including the locale, e.g.
+// en.messages.hello, in place of the generated localizations.
+type Source interface {
+	Lookup(key string) (string, bool)
+}
+
+// Translator is implemented by Localizer, so code can depend on the interface
+// and swap in a test double.
+type Translator interface {
+	Get(key string, replacements ...*Replacements) string
+	GetWithLocale(locale, key string, replacements ...*Replacements) string
+	Lookup(key string, replacements ...*Replacements) (string, bool)
+	GetHTML(key string, replacements ...*Replacements) htmltemplate.HTML
+	Getf(key string, args ...interface{}) string
+	GetSelect(key, selector string, replacements ...*Replacements) string
+	GetOrdinal(key string, n int, replacements ...*Replacements) string
+	GetPlural(key string, n int, replacements ...*Replacements) string
+	Direction() Direction
+}
+
+// Direction is the direction a locale's text is written in, e.g. for the dir
+// HTML attribute.
+type Direction string
+
+const (
+	LeftToRight Direction = "ltr"
+	RightToLeft Direction = "rtl"
+)
+
+var _ Translator = Localizer{}
+
+// Localizer is safe for concurrent use. The localizations it is created with
+// are shared by every Localizer and never modified, use WithOverrides to
+// change localizations for a single Localizer.
+type Localizer struct {
+	Locale         string
+	FallbackLocale string
+	localizations  map[string]string
+	overrides      map[string]string
+	source         Source
+	scope          string
+	isolate        bool
+}
+
+func New(locale string, fallbackLocale string) *Localizer {
+	t := &Localizer{Locale: locale, FallbackLocale: fallbackLocale}
+	t.localizations = localizations
+	return t
+}
+
+func (t Localizer) SetLocales(locale, fallback string) Localizer {
+	t.Locale = locale
+	t.FallbackLocale = fallback
+	return t
+}
+
+func (t Localizer) SetLocale(locale string) Localizer {
+	t.Locale = locale
+	return t
+}
+
+func (t Localizer) SetFallbackLocale(fallback string) Localizer {
+	t.FallbackLocale = fallback
+	return t
+}
+
+// WithSource returns the Localizer looking up localizations in source, such as
+// a catalog loaded at runtime, instead of the generated localizations.
+func (t Localizer) WithSource(source Source) Localizer {
+	t.source = source
+	return t
+}
+
+// Scope returns the Localizer prefixing keys with prefix, e.g. with the
+// customer.messages scope Get("hello") gets customer.messages.hello. Scopes
+// compose, so scoping a scoped Localizer appends to its prefix.
+func (t Localizer) Scope(prefix string) Localizer {
+	prefix = strings.Trim(prefix, ".")
+	if prefix != "" {
+		t.scope += prefix + "."
+	}
+	return t
+}
+
+// WithBidiIsolation returns the Localizer wrapping replacements and Getf
+// arguments in the FSI and PDI bidi isolate characters when rendering for a
+// right-to-left locale, so left-to-right values like emails and order IDs
+// don't garble the text around them. Only the output of replacements is
+// isolated, so they can still be compared in template logic, e.g. with eq.
+func (t Localizer) WithBidiIsolation() Localizer {
+	t.isolate = true
+	return t
+}
+
+// Direction returns the direction of the Localizer's locale.
+func (t Localizer) Direction() Direction {
+	return direction(t.Locale)
+}
+
+// WithOverrides returns the Localizer with the overrides, keyed including the
+// locale, e.g. en.messages.hello, taking precedence over its localizations.
+// The overrides are copied, so neither t nor overrides are modified.
+func (t Localizer) WithOverrides(overrides map[string]string) Localizer {
+	merged := make(map[string]string, len(t.overrides)+len(overrides))
+	for key, value := range t.overrides {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		merged[key] = value
+	}
+	t.overrides = merged
+	return t
+}
+
+func (t Localizer) GetWithLocale(locale, key string, replacements ...*Replacements) string {
+	key = t.scope + key
+	str, ok := t.resolve(locale, key, 0, replacements...)
+	if !ok {
+		return key
+	}
+	return str
+}
+
+func (t Localizer) Get(key string, replacements ...*Replacements) string {
+	str := t.GetWithLocale(t.Locale, key, replacements...)
+	return str
+}
+
+// Lookup is Get, but reports whether key was found in the locale or the
+// fallback locale instead of returning the key.
+func (t Localizer) Lookup(key string, replacements ...*Replacements) (string, bool) {
+	return t.resolve(t.Locale, t.scope+key, 0, replacements...)
+}
+
+// GetHTML is Get, but escaped for use in HTML. Replacements are always
+// escaped, the localization itself only when key doesn't end in _html, which
+// marks the markup in its localizations as trusted.
+func (t Localizer) GetHTML(key string, replacements ...*Replacements) htmltemplate.HTML {
+	key = t.scope + key
+	str, ok := t.resolveHTML(t.Locale, key, 0, replacements...)
+	if !ok {
+		return htmltemplate.HTML(htmltemplate.HTMLEscapeString(key))
+	}
+	return str
+}
+
+// Getf returns the localization of key formatted with args using fmt, for
+// localizations using verbs like %s or %[2]d instead of replacements.
+func (t Localizer) Getf(key string, args ...interface{}) string {
+	key = t.scope + key
+	str, ok := t.resolve(t.Locale, key, 0)
+	if !ok {
+		return key
+	}
+	if t.isolate && direction(t.Locale) == RightToLeft {
+		// Width and precision arguments, e.g. of %*d, must stay ints.
+		stars := starArgs(str)
+		isolatedArgs := make([]interface{}, len(args))
+		for i, arg := range args {
+			isolatedArgs[i] = arg
+			if !stars[i] {
+				isolatedArgs[i] = isolated{arg}
+			}
+		}
+		args = isolatedArgs
+	}
+	return fmt.Sprintf(str, args...)
+}
+
+// GetSelect returns the select case of key matching selector, e.g. for
+// grammatical gender, or otherwise its other case. Select cases are declared
+// as a nested object with a required other case in the translation files, so
+// with
+//
+//	updated: {female: "updated her profile", other: "updated their profile"}
+//
+// GetSelect("messages.updated", "female") gets messages.updated.female.
+func (t Localizer) GetSelect(key, selector string, replacements ...*Replacements) string {
+	key = t.scope + key
+	for _, locale := range []string{t.Locale, t.FallbackLocale} {
+		for _, c := range []string{selector, "other"} {
+			if str, ok := t.lookup(locale, key+"."+c); ok {
+				return t.render(locale, str, 0, replacements...)
+			}
+		}
+	}
+	return key
+}
+
+// GetOrdinal returns the case of key for the CLDR ordinal category of n in
+// the locale, e.g. one for 1st, two for 2nd, few for 3rd and other for 4th in
+// English, or otherwise its other case. Cases are declared like select cases,
+// and n is available to them as the n replacement.
+func (t Localizer) GetOrdinal(key string, n int, replacements ...*Replacements) string {
+	key = t.scope + key
+	ordinal := Replacements{"n": n}
+	replacements = append([]*Replacements{&ordinal}, replacements...)
+	for _, locale := range []string{t.Locale, t.FallbackLocale} {
+		for _, c := range []string{ordinalCategory(locale, n), "other"} {
+			if str, ok := t.lookup(locale, key+"."+c); ok {
+				return t.render(locale, str, 0, replacements...)
+			}
+		}
+	}
+	return key
+}
+
+// GetPlural returns the case of key for the count n, e.g. items.0 if there is
+// a case for exactly n, otherwise the case of the CLDR cardinal category of n
+// in the locale, e.g. one for 1 and other for 2 in English, or otherwise its
+// other case. Plurals loaded from Android, Apple, ARB and Fluent files are
+// declared this way. n is available to the cases as the n replacement and as
+// the replacement they are selected by, e.g. count in ARB files or arg1 for
+// %d in Android files, unless replacements set it.
+func (t Localizer) GetPlural(key string, n int, replacements ...*Replacements) string {
+	key = t.scope + key
+	plural := Replacements{"n": n, pluralSelector(key): n}
+	replacements = append([]*Replacements{&plural}, replacements...)
+	for _, locale := range []string{t.Locale, t.FallbackLocale} {
+		for _, c := range []string{fmt.Sprint(n), cardinalCategory(locale, n), "other"} {
+			if str, ok := t.lookup(locale, key+"."+c); ok {
+				return t.render(locale, str, 0, replacements...)
+			}
+		}
+	}
+	return key
+}
+
+// resolve looks up key in locale or otherwise the fallback locale, and
+// replaces its substitutions and references to other keys.
+func (t Localizer) resolve(locale, key string, depth int, replacements ...*Replacements) (string, bool) {
+	str, ok := t.find(locale, key)
+	if !ok {
+		return "", false
+	}
+	return t.render(locale, str, depth, replacements...), true
+}
+
+// render replaces the substitutions and references to other keys of str.
+func (t Localizer) render(locale, str string, depth int, replacements ...*Replacements) string {
+	// If the str doesn't have any substitutions, no need to
+	// template.Execute.
+	if strings.Index(str, "}}") == -1 {
+		return str
+	}
+
+	return t.replace(locale, str, depth, replacements...)
+}
+
+// resolveHTML is resolve, but escaped for use in HTML.
+func (t Localizer) resolveHTML(locale, key string, depth int, replacements ...*Replacements) (htmltemplate.HTML, bool) {
+	if !strings.HasSuffix(key, "_html") {
+		str, ok := t.resolve(locale, key, depth, replacements...)
+		return htmltemplate.HTML(htmltemplate.HTMLEscapeString(str)), ok
+	}
+
+	str, ok := t.find(locale, key)
+	if !ok {
+		return "", false
+	}
+	if strings.Index(str, "}}") == -1 {
+		return htmltemplate.HTML(str), true
+	}
+
+	return t.replaceHTML(locale, str, depth, replacements...), true
+}
+
+// find looks up key in locale or otherwise the fallback locale.
+func (t Localizer) find(locale, key string) (string, bool) {
+	str, ok := t.lookup(locale, key)
+	if !ok {
+		str, ok = t.lookup(t.FallbackLocale, key)
+	}
+	return str, ok
+}
+
+func (t Localizer) lookup(locale, key string) (string, bool) {
+	if str, ok := t.overrides[t.getLocalizationKey(locale, key)]; ok {
+		return str, true
+	}
+	if t.source != nil {
+		return t.source.Lookup(t.getLocalizationKey(locale, key))
+	}
+
+	str, ok := t.localizations[t.getLocalizationKey(locale, key)]
+{{- if .Embed }}
+	if !ok {
+		str, ok = loadLocale(locale)[key]
+	}
+{{- end }}
+	return str, ok
+}
+
+func (t Localizer) getLocalizationKey(locale string, key string) string {
+	return fmt.Sprintf("%v.%v", locale, key)
+}
+
+// ordinalRules are the CLDR ordinal plural rules by language, returning the
+// category of n. Languages without a rule only use other.
+var ordinalRules = map[string]func(n int) string{
+	"en": func(n int) string {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 == 2 && n%100 != 12:
+			return "two"
+		case n%10 == 3 && n%100 != 13:
+			return "few"
+		}
+		return "other"
+	},
+	"fr":  ordinalOne,
+	"ga":  ordinalOne,
+	"hy":  ordinalOne,
+	"lo":  ordinalOne,
+	"ms":  ordinalOne,
+	"ro":  ordinalOne,
+	"vi":  ordinalOne,
+	"fil": ordinalOne,
+	"tl":  ordinalOne,
+	"it": func(n int) string {
+		if n == 11 || n == 8 || n == 80 || n == 800 {
+			return "many"
+		}
+		return "other"
+	},
+	"sv": func(n int) string {
+		if (n%10 == 1 || n%10 == 2) && n%100 != 11 && n%100 != 12 {
+			return "one"
+		}
+		return "other"
+	},
+	"hu": func(n int) string {
+		if n == 1 || n == 5 {
+			return "one"
+		}
+		return "other"
+	},
+	"ca": func(n int) string {
+		switch n {
+		case 1, 3:
+			return "one"
+		case 2:
+			return "two"
+		case 4:
+			return "few"
+		}
+		return "other"
+	},
+	"cy": func(n int) string {
+		switch n {
+		case 0, 7, 8, 9:
+			return "zero"
+		case 1:
+			return "one"
+		case 2:
+			return "two"
+		case 3, 4:
+			return "few"
+		case 5, 6:
+			return "many"
+		}
+		return "other"
+	},
+	"hi": ordinalHindi,
+	"gu": ordinalHindi,
+	"mr": func(n int) string {
+		switch n {
+		case 1:
+			return "one"
+		case 2, 3:
+			return "two"
+		case 4:
+			return "few"
+		}
+		return "other"
+	},
+	"bn": ordinalBengali,
+	"as": ordinalBengali,
+	"ne": func(n int) string {
+		if n >= 1 && n <= 4 {
+			return "one"
+		}
+		return "other"
+	},
+	"sq": func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n%10 == 4 && n%100 != 14:
+			return "many"
+		}
+		return "other"
+	},
+	"mk": func(n int) string {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 == 2 && n%100 != 12:
+			return "two"
+		case (n%10 == 7 || n%10 == 8) && n%100 != 17 && n%100 != 18:
+			return "many"
+		}
+		return "other"
+	},
+	"kk": func(n int) string {
+		if n%10 == 6 || n%10 == 9 || (n%10 == 0 && n != 0) {
+			return "many"
+		}
+		return "other"
+	},
+	"uk": func(n int) string {
+		if n%10 == 3 && n%100 != 13 {
+			return "few"
+		}
+		return "other"
+	},
+	"be": func(n int) string {
+		if (n%10 == 2 || n%10 == 3) && n%100 != 12 && n%100 != 13 {
+			return "few"
+		}
+		return "other"
+	},
+	"tk": func(n int) string {
+		if n%10 == 6 || n%10 == 9 || n == 10 {
+			return "few"
+		}
+		return "other"
+	},
+	"ka": func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n == 0 || (n%100 >= 2 && n%100 <= 20) || n%100 == 40 || n%100 == 60 || n%100 == 80:
+			return "many"
+		}
+		return "other"
+	},
+	"az": func(n int) string {
+		switch {
+		case n%10 == 1 || n%10 == 2 || n%10 == 5 || n%10 == 7 || n%10 == 8 ||
+			n%100 == 20 || n%100 == 50 || n%100 == 70 || n%100 == 80:
+			return "one"
+		case n%10 == 3 || n%10 == 4 || (n%1000 != 0 && n%100 == 0):
+			return "few"
+		case n == 0 || n%10 == 6 || n%100 == 40 || n%100 == 60 || n%100 == 90:
+			return "many"
+		}
+		return "other"
+	},
+}
+
+func ordinalOne(n int) string {
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func ordinalHindi(n int) string {
+	switch n {
+	case 1:
+		return "one"
+	case 2, 3:
+		return "two"
+	case 4:
+		return "few"
+	case 6:
+		return "many"
+	}
+	return "other"
+}
+
+func ordinalBengali(n int) string {
+	switch n {
+	case 1, 5, 7, 8, 9, 10:
+		return "one"
+	case 2, 3:
+		return "two"
+	case 4:
+		return "few"
+	case 6:
+		return "many"
+	}
+	return "other"
+}
+
+// ordinalCategory returns the CLDR ordinal category of n in locale, using the
+// rules of its language, e.g. en for en-GB.
+func ordinalCategory(locale string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	language := strings.ToLower(locale)
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	if rule, ok := ordinalRules[language]; ok {
+		return rule(n)
+	}
+	return "other"
+}
+
+// cardinalRules are the CLDR cardinal plural rules of whole numbers by
+// language, or by locale for the ones that differ from their language, e.g.
+// pt-pt, returning the category of n. Languages without a rule, e.g. ja or
+// zh, only use other.
+var cardinalRules = map[string]func(n int) string{
+	"af":    cardinalOne,
+	"bg":    cardinalOne,
+	"da":    cardinalOne,
+	"de":    cardinalOne,
+	"el":    cardinalOne,
+	"en":    cardinalOne,
+	"et":    cardinalOne,
+	"eu":    cardinalOne,
+	"fi":    cardinalOne,
+	"gl":    cardinalOne,
+	"hu":    cardinalOne,
+	"ka":    cardinalOne,
+	"nb":    cardinalOne,
+	"nl":    cardinalOne,
+	"nn":    cardinalOne,
+	"no":    cardinalOne,
+	"sq":    cardinalOne,
+	"sv":    cardinalOne,
+	"sw":    cardinalOne,
+	"tr":    cardinalOne,
+	"ur":    cardinalOne,
+	"ca":    cardinalOneMany,
+	"es":    cardinalOneMany,
+	"it":    cardinalOneMany,
+	"pt-pt": cardinalOneMany,
+	"fr":    cardinalZeroOneMany,
+	"pt":    cardinalZeroOneMany,
+	"am":    cardinalZeroOne,
+	"bn":    cardinalZeroOne,
+	"fa":    cardinalZeroOne,
+	"gu":    cardinalZeroOne,
+	"hi":    cardinalZeroOne,
+	"kn":    cardinalZeroOne,
+	"zu":    cardinalZeroOne,
+	"is":    cardinalOneTens,
+	"mk":    cardinalOneTens,
+	"be":    cardinalSlavic,
+	"ru":    cardinalSlavic,
+	"uk":    cardinalSlavic,
+	"bs":    cardinalSerbian,
+	"hr":    cardinalSerbian,
+	"sr":    cardinalSerbian,
+	"cs":    cardinalCzech,
+	"sk":    cardinalCzech,
+	"pl": func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		}
+		return "many"
+	},
+	"lt": func(n int) string {
+		switch {
+		case n%100 >= 11 && n%100 <= 19:
+			return "other"
+		case n%10 == 1:
+			return "one"
+		case n%10 >= 2:
+			return "few"
+		}
+		return "other"
+	},
+	"lv": func(n int) string {
+		switch {
+		case n%10 == 0 || (n%100 >= 11 && n%100 <= 19):
+			return "zero"
+		case n%10 == 1:
+			return "one"
+		}
+		return "other"
+	},
+	"ro": func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n == 0 || (n%100 >= 2 && n%100 <= 19):
+			return "few"
+		}
+		return "other"
+	},
+	"sl": func(n int) string {
+		switch n % 100 {
+		case 1:
+			return "one"
+		case 2:
+			return "two"
+		case 3, 4:
+			return "few"
+		}
+		return "other"
+	},
+	"he": func(n int) string {
+		switch n {
+		case 1:
+			return "one"
+		case 2:
+			return "two"
+		}
+		return "other"
+	},
+	"ar": func(n int) string {
+		switch {
+		case n == 0:
+			return "zero"
+		case n == 1:
+			return "one"
+		case n == 2:
+			return "two"
+		case n%100 >= 3 && n%100 <= 10:
+			return "few"
+		case n%100 >= 11:
+			return "many"
+		}
+		return "other"
+	},
+	"ga": func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n == 2:
+			return "two"
+		case n >= 3 && n <= 6:
+			return "few"
+		case n >= 7 && n <= 10:
+			return "many"
+		}
+		return "other"
+	},
+	"cy": func(n int) string {
+		switch n {
+		case 0:
+			return "zero"
+		case 1:
+			return "one"
+		case 2:
+			return "two"
+		case 3:
+			return "few"
+		case 6:
+			return "many"
+		}
+		return "other"
+	},
+}
+
+func cardinalOne(n int) string {
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func cardinalZeroOne(n int) string {
+	if n == 0 || n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func cardinalOneMany(n int) string {
+	switch {
+	case n == 1:
+		return "one"
+	case n != 0 && n%1000000 == 0:
+		return "many"
+	}
+	return "other"
+}
+
+func cardinalZeroOneMany(n int) string {
+	switch {
+	case n == 0 || n == 1:
+		return "one"
+	case n%1000000 == 0:
+		return "many"
+	}
+	return "other"
+}
+
+func cardinalOneTens(n int) string {
+	if n%10 == 1 && n%100 != 11 {
+		return "one"
+	}
+	return "other"
+}
+
+func cardinalSlavic(n int) string {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return "one"
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return "few"
+	}
+	return "many"
+}
+
+func cardinalSerbian(n int) string {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return "one"
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return "few"
+	}
+	return "other"
+}
+
+func cardinalCzech(n int) string {
+	switch {
+	case n == 1:
+		return "one"
+	case n >= 2 && n <= 4:
+		return "few"
+	}
+	return "other"
+}
+
+// cardinalCategory returns the CLDR cardinal category of n in locale, using
+// the rules of the locale if it has its own, e.g. pt-PT, or otherwise of its
+// language, e.g. en for en-GB.
+func cardinalCategory(locale string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	if rule, ok := cardinalRules[locale]; ok {
+		return rule(n)
+	}
+	language := locale
+	if i := strings.IndexByte(language, '-'); i >= 0 {
+		language = language[:i]
+	}
+	if rule, ok := cardinalRules[language]; ok {
+		return rule(n)
+	}
+	return "other"
+}
+
+// maxReferenceDepth limits how deeply references to other keys are followed, in
+// case overrides or a source added a cycle the generator could not detect.
+const maxReferenceDepth = 16
+
+func (t Localizer) replace(locale, str string, depth int, replacements ...*Replacements) string {
+	b := &bytes.Buffer{}
+	tmpl, err := template.New("").Funcs(template.FuncMap{
+		// t is replaced by the localization of the key in the same locale,
+		// with the same fallback and replacements.
+		"t": func(key string) (string, error) {
+			if depth >= maxReferenceDepth {
+				return "", fmt.Errorf("references nested more than %d deep", maxReferenceDepth)
+			}
+			str, ok := t.resolve(locale, key, depth+1, replacements...)
+			if !ok {
+				return key, nil
+			}
+			return str, nil
+		},
+		"isolate": isolate,
+	}).Parse(str)
+	if err != nil {
+		return str
+	}
+	if t.isolate && direction(locale) == RightToLeft {
+		isolateActions(tmpl.Tree, tmpl.Tree.Root)
+	}
+
+	err = template.Must(tmpl, err).Execute(b, t.replacements(replacements))
+	if err != nil {
+		return str
+	}
+	buff := b.String()
+	return buff
+}
+
+// replaceHTML is replace using html/template, so the replacements and
+// references to keys not ending in _html are escaped for the context they are
+// used in.
+func (t Localizer) replaceHTML(locale, str string, depth int, replacements ...*Replacements) htmltemplate.HTML {
+	b := &bytes.Buffer{}
+	tmpl, err := htmltemplate.New("").Funcs(htmltemplate.FuncMap{
+		"t": func(key string) (htmltemplate.HTML, error) {
+			if depth >= maxReferenceDepth {
+				return "", fmt.Errorf("references nested more than %d deep", maxReferenceDepth)
+			}
+			str, ok := t.resolveHTML(locale, key, depth+1, replacements...)
+			if !ok {
+				return htmltemplate.HTML(htmltemplate.HTMLEscapeString(key)), nil
+			}
+			return str, nil
+		},
+		"isolate": isolate,
+	}).Parse(str)
+	if err != nil {
+		return htmltemplate.HTML(str)
+	}
+	if t.isolate && direction(locale) == RightToLeft {
+		isolateActions(tmpl.Tree, tmpl.Tree.Root)
+	}
+
+	err = tmpl.Execute(b, t.replacements(replacements))
+	if err != nil {
+		return htmltemplate.HTML(str)
+	}
+	return htmltemplate.HTML(b.String())
+}
+
+// replacements merges the replacements to render.
+func (t Localizer) replacements(replacements []*Replacements) Replacements {
+	replacementsMerge := Replacements{}
+	for _, replacement := range replacements {
+		for k, v := range *replacement {
+			replacementsMerge[k] = v
+		}
+	}
+	return replacementsMerge
+}
+
+// isolateActions pipes the output of the actions in node through isolate,
+// other than references to other keys, which are isolated themselves.
+func isolateActions(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, node := range n.Nodes {
+			isolateActions(tree, node)
+		}
+	case *parse.IfNode:
+		isolateActions(tree, n.List)
+		isolateActions(tree, n.ElseList)
+	case *parse.RangeNode:
+		isolateActions(tree, n.List)
+		isolateActions(tree, n.ElseList)
+	case *parse.WithNode:
+		isolateActions(tree, n.List)
+		isolateActions(tree, n.ElseList)
+	case *parse.ActionNode:
+		// Declarations of variables don't output anything.
+		if len(n.Pipe.Decl) > 0 {
+			return
+		}
+		last := n.Pipe.Cmds[len(n.Pipe.Cmds)-1]
+		if id, ok := last.Args[0].(*parse.IdentifierNode); ok && id.Ident == "t" {
+			return
+		}
+		isolate := parse.NewIdentifier("isolate").SetTree(tree).SetPos(n.Pos)
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pos,
+			Args:     []parse.Node{isolate},
+		})
+	}
+}
+
+// isolate formats v wrapped in the FSI and PDI bidi isolate characters, so it
+// is displayed in its own direction.
+func isolate(v interface{}) string {
+	return "\u2068" + fmt.Sprint(v) + "\u2069"
+}
+
+// isolated formats its value wrapped in the FSI and PDI bidi isolate
+// characters, so it is displayed in its own direction.
+type isolated struct {
+	value interface{}
+}
+
+func (i isolated) Format(f fmt.State, verb rune) {
+	format := "%"
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			format += string(flag)
+		}
+	}
+	if width, ok := f.Width(); ok {
+		format += fmt.Sprint(width)
+	}
+	if precision, ok := f.Precision(); ok {
+		format += "." + fmt.Sprint(precision)
+	}
+	fmt.Fprintf(f, "\u2068"+format+string(verb)+"\u2069", i.value)
+}
+
+// starArgs returns the indexes of the arguments of format used as the width
+// or precision of a verb, e.g. 0 for %*d, including explicit argument indexes,
+// e.g. 1 for %[2]*[1]d.
+func starArgs(format string) map[int]bool {
+	stars := map[int]bool{}
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		// index parses an explicit argument index, e.g. [2].
+		index := func() {
+			if i >= len(format) || format[i] != '[' {
+				return
+			}
+			end := strings.IndexByte(format[i:], ']')
+			if end < 0 {
+				return
+			}
+			if n, err := strconv.Atoi(format[i+1 : i+end]); err == nil {
+				arg = n - 1
+			}
+			i += end + 1
+		}
+		// size parses a width or precision, consuming an argument if it is *.
+		size := func() {
+			index()
+			if i < len(format) && format[i] == '*' {
+				stars[arg] = true
+				arg++
+				i++
+				return
+			}
+			for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+				i++
+			}
+		}
+		size()
+		if i < len(format) && format[i] == '.' {
+			i++
+			size()
+		}
+		index()
+		arg++
+	}
+	return stars
+}
+
+// rtlLanguages and rtlScripts are the languages and scripts written
+// right-to-left.
+var (
+	rtlLanguages = map[string]bool{
+		"ar": true, "arc": true, "ckb": true, "dv": true, "fa": true, "he": true,
+		"iw": true, "ks": true, "nqo": true, "ps": true, "sd": true, "syr": true,
+		"ug": true, "ur": true, "yi": true,
+	}
+	rtlScripts = map[string]bool{
+		"adlm": true, "arab": true, "hebr": true, "mand": true, "nkoo": true,
+		"rohg": true, "samr": true, "syrc": true, "thaa": true,
+	}
+)
+
+// direction returns the direction of locale, from its script subtag if it
+// has one, e.g. az-Arab, or otherwise its language.
+func direction(locale string) Direction {
+	subtags := strings.FieldsFunc(strings.ToLower(locale), func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	if len(subtags) == 0 {
+		return LeftToRight
+	}
+	for _, subtag := range subtags[1:] {
+		if len(subtag) == 4 {
+			if rtlScripts[subtag] {
+				return RightToLeft
+			}
+			return LeftToRight
+		}
+	}
+	if rtlLanguages[subtags[0]] {
+		return RightToLeft
+	}
+	return LeftToRight
+}
+`,
+))
+
+var localeTemplate = template.Must(template.New("").Parse(generatedHeader + `
+// This file was generated by robots at
+// {{ .Timestamp }}
+{{ if .BuildTag }}
+//go:build {{ .BuildTag }} || i18n_all
+// +build {{ .BuildTag }} i18n_all
+{{ end }}
+package {{ .Package }}
+
+func init() {
+	registerLocale("{{ .Locale }}", map[string]string{
+{{- range $key, $element := .Localizations  }}
+		{{ printf "%q" $key }}:{{ call $.LineUp $key }} {{ printf "%q" $element }},
+{{- end }}
+	})
+}
+`,
+))
+
+var fakeTemplate = template.Must(template.New("").Parse(generatedHeader + `
+// This file was generated by robots at
+// {{ .Timestamp }}
+
+// Package {{ .Package }}test provides a test double for the {{ .Package }}
+// package.
+package {{ .Package }}test
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"sync"
+
+	"{{ .ImportPath }}"
+)
+
+// Translator is a {{ .Package }}.Translator test double, recording the keys
+// requested and returning configurable values.
+type Translator struct {
+	// Values are the values returned by key. A key without a value is
+	// returned as is, the same as a Localizer does for missing keys.
+	Values map[string]string
+	// Dir is returned by Direction, defaulting to left-to-right.
+	Dir {{ .Package }}.Direction
+
+	mu   sync.Mutex
+	keys []string
+}
+
+var _ {{ .Package }}.Translator = &Translator{}
+
+// New returns a Translator returning the values by key.
+func New(values map[string]string) *Translator {
+	return &Translator{Values: values}
+}
+
+func (t *Translator) Get(key string, replacements ...*{{ .Package }}.Replacements) string {
+	str, ok := t.Lookup(key, replacements...)
+	if !ok {
+		return key
+	}
+	return str
+}
+
+// GetWithLocale returns the value of key in locale, e.g. es.messages.hello,
+// or otherwise the value of key.
+func (t *Translator) GetWithLocale(locale, key string, replacements ...*{{ .Package }}.Replacements) string {
+	if str, ok := t.value(locale + "." + key); ok {
+		t.record(key)
+		return str
+	}
+	return t.Get(key, replacements...)
+}
+
+func (t *Translator) Lookup(key string, replacements ...*{{ .Package }}.Replacements) (string, bool) {
+	t.record(key)
+	return t.value(key)
+}
+
+func (t *Translator) Getf(key string, args ...interface{}) string {
+	str, ok := t.Lookup(key)
+	if !ok {
+		return key
+	}
+	return fmt.Sprintf(str, args...)
+}
+
+// GetSelect returns the value of key.selector, or otherwise key.other.
+func (t *Translator) GetSelect(key, selector string, replacements ...*{{ .Package }}.Replacements) string {
+	t.record(key)
+	for _, c := range []string{selector, "other"} {
+		if str, ok := t.value(key + "." + c); ok {
+			return str
+		}
+	}
+	return key
+}
+
+// GetOrdinal returns the value of key.n, e.g. messages.ranked.1, or otherwise
+// key.other, as the fake has no ordinal rules.
+func (t *Translator) GetOrdinal(key string, n int, replacements ...*{{ .Package }}.Replacements) string {
+	return t.GetSelect(key, fmt.Sprint(n), replacements...)
+}
+
+// GetPlural returns the value of key.n, e.g. messages.items.0, or otherwise
+// key.other, as the fake has no cardinal rules.
+func (t *Translator) GetPlural(key string, n int, replacements ...*{{ .Package }}.Replacements) string {
+	return t.GetSelect(key, fmt.Sprint(n), replacements...)
+}
+
+// GetHTML is Get, escaped unless key ends in _html, the same as a Localizer.
+func (t *Translator) GetHTML(key string, replacements ...*{{ .Package }}.Replacements) template.HTML {
+	str := t.Get(key, replacements...)
+	if strings.HasSuffix(key, "_html") {
+		return template.HTML(str)
+	}
+	return template.HTML(template.HTMLEscapeString(str))
+}
+
+func (t *Translator) Direction() {{ .Package }}.Direction {
+	if t.Dir == "" {
+		return {{ .Package }}.LeftToRight
+	}
+	return t.Dir
+}
+
+// Keys returns the keys requested, in order.
+func (t *Translator) Keys() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.keys...)
+}
+
+// Reset forgets the keys requested.
+func (t *Translator) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.keys = nil
+}
+
+func (t *Translator) record(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.keys = append(t.keys, key)
+}
+
+func (t *Translator) value(key string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	str, ok := t.Values[key]
+	return str, ok
+}
+`,
+))
+
+var typingsTemplate = template.Must(template.New("").Parse(generatedHeader + `
+
+// LocalizationParams are the replacements of every localization key.
+export interface LocalizationParams {
+{{- range .Keys }}
+  {{ printf "%q" .Key }}: {{ .Type }};
+{{- end }}
+}
+
+// LocalizationKey is every localization key.
+export type LocalizationKey = keyof LocalizationParams;
+`,
+))
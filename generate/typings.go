@@ -0,0 +1,114 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// typingsFilename is the file name of the TypeScript typings.
+const typingsFilename = "localizations.d.ts"
+
+// typingsData is the data the typings template is executed with.
+type typingsData struct {
+	Keys []typingsKey
+}
+
+// typingsKey is a localization key and the TypeScript type of its
+// placeholders.
+type typingsKey struct {
+	Key  string
+	Type string
+}
+
+// writeTypings writes the TypeScript typings of every key of the
+// localizations and its placeholders to dir.
+func writeTypings(dir string, localizations map[string]string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(dir, typingsFilename))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return typingsTemplate.Execute(f, newTypingsData(localizations))
+}
+
+// newTypingsData returns the keys of every locale with the placeholders used
+// by any locale, including those of the keys they reference.
+func newTypingsData(localizations map[string]string) typingsData {
+	fields := map[string]map[string]bool{}
+	refs := map[string]map[string]bool{}
+	for _, l := range splitLocales(localizations) {
+		for key, value := range l {
+			if fields[key] == nil {
+				fields[key] = map[string]bool{}
+				refs[key] = map[string]bool{}
+			}
+			for _, field := range placeholders(value) {
+				fields[key][field] = true
+			}
+			for _, ref := range references(value) {
+				refs[key][ref] = true
+			}
+		}
+	}
+
+	var collect func(key string, params, visited map[string]bool)
+	collect = func(key string, params, visited map[string]bool) {
+		if visited[key] {
+			return
+		}
+		visited[key] = true
+		for field := range fields[key] {
+			params[field] = true
+		}
+		for ref := range refs[key] {
+			collect(ref, params, visited)
+		}
+	}
+
+	data := typingsData{}
+	for key := range fields {
+		params := map[string]bool{}
+		collect(key, params, map[string]bool{})
+		data.Keys = append(data.Keys, typingsKey{Key: key, Type: paramsType(params)})
+	}
+	sort.Slice(data.Keys, func(i, j int) bool {
+		return data.Keys[i].Key < data.Keys[j].Key
+	})
+	return data
+}
+
+// placeholders returns the names of the replacements str substitutes, e.g.
+// name for {{.name}}.
+func placeholders(str string) []string {
+	var names []string
+	walkTemplate(str, func(node parse.Node) {
+		if field, ok := node.(*parse.FieldNode); ok && len(field.Ident) > 0 {
+			names = append(names, field.Ident[0])
+		}
+	})
+	return names
+}
+
+// paramsType returns the TypeScript type of the replacements of params.
+func paramsType(params map[string]bool) string {
+	if len(params) == 0 {
+		return "Record<string, never>"
+	}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		names[i] = name + ": string | number"
+	}
+	return "{ " + strings.Join(names, "; ") + " }"
+}
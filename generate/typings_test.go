@@ -0,0 +1,78 @@
+package generate
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_writeTypings(t *testing.T) {
+	dir := "test_files/typings"
+	err := writeTypings(dir, map[string]string{
+		"en.messages.hello": "hello {{.name}}",
+		"es.messages.bye":   "adiós",
+	})
+	if err != nil {
+		t.Fatalf("writeTypings() error = %v", err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, typingsFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"// Code generated by go-localize; DO NOT EDIT.",
+		`  "messages.bye": Record<string, never>;`,
+		`  "messages.hello": { name: string | number };`,
+		"export type LocalizationKey = keyof LocalizationParams;",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("writeTypings() got = %v, want it to contain %v", string(b), want)
+		}
+	}
+}
+
+func Test_newTypingsData(t *testing.T) {
+	tests := []struct {
+		name          string
+		localizations map[string]string
+		want          typingsData
+	}{
+		{
+			name: "placeholders of every locale",
+			localizations: map[string]string{
+				"en.messages.hello": "hello {{.name}}",
+				"es.messages.hello": "hola {{.firstname}} {{.name}}",
+				"es.messages.bye":   "adiós",
+			},
+			want: typingsData{Keys: []typingsKey{
+				{Key: "messages.bye", Type: "Record<string, never>"},
+				{Key: "messages.hello", Type: "{ firstname: string | number; name: string | number }"},
+			}},
+		},
+		{
+			name: "placeholders of references",
+			localizations: map[string]string{
+				"en.brand.name":     "{{.brand}}",
+				"en.messages.hello": `{{t "brand.name"}} {{if .name}}{{.name}}{{end}}`,
+				"es.messages.a":     `{{t "messages.b"}}`,
+				"en.messages.b":     `{{t "messages.a"}}`,
+			},
+			want: typingsData{Keys: []typingsKey{
+				{Key: "brand.name", Type: "{ brand: string | number }"},
+				{Key: "messages.a", Type: "Record<string, never>"},
+				{Key: "messages.b", Type: "Record<string, never>"},
+				{Key: "messages.hello", Type: "{ brand: string | number; name: string | number }"},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTypingsData(tt.localizations); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newTypingsData() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
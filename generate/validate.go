@@ -0,0 +1,126 @@
+package generate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/m1/go-localize/loader"
+)
+
+// Validation is a check of the localizations that fails generation.
+type Validation string
+
+const (
+	// ValidationVerbs checks that every locale of a key uses the same fmt
+	// verbs, for localizations used with Getf.
+	ValidationVerbs Validation = "verbs"
+	// ValidationMissingKeys checks that every locale has the keys of the base
+	// locale.
+	ValidationMissingKeys Validation = "missing_keys"
+)
+
+// validate runs the validations of the localizations.
+func validate(localizations map[string]string, validations []Validation, baseLocale string) error {
+	for _, v := range validations {
+		var err error
+		switch v {
+		case ValidationVerbs:
+			err = checkVerbs(localizations)
+		case ValidationMissingKeys:
+			err = checkMissingKeys(localizations, baseLocale)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkValidations returns ErrInvalidValidation for unknown validations, and
+// ErrNoBaseLocale if a validation needs the base locale and it's not set.
+func checkValidations(validations []Validation, baseLocale string) error {
+	for _, v := range validations {
+		switch v {
+		case ValidationVerbs:
+		case ValidationMissingKeys:
+			if baseLocale == "" {
+				return fmt.Errorf("%w: %v", ErrNoBaseLocale, v)
+			}
+		default:
+			return fmt.Errorf("%w: %q", ErrInvalidValidation, v)
+		}
+	}
+	return nil
+}
+
+// checkMissingKeys returns ErrMissingKeys if a locale doesn't have every key
+// of the base locale. The cases of a select are only needed for its other
+// case, as locales have different plural categories.
+func checkMissingKeys(localizations map[string]string, baseLocale string) error {
+	locales := splitLocales(localizations)
+	base, ok := locales[baseLocale]
+	if !ok {
+		return fmt.Errorf("%w: %v has no localizations", ErrMissingKeys, baseLocale)
+	}
+	names := make([]string, 0, len(locales))
+	for locale := range locales {
+		names = append(names, locale)
+	}
+	sort.Strings(names)
+
+	for _, locale := range names {
+		var missing []string
+		for key := range base {
+			if _, ok := locales[locale][key]; ok {
+				continue
+			}
+			if i := strings.LastIndexByte(key, '.'); i >= 0 && key[i+1:] != loader.OtherCase {
+				if _, isSelect := base[key[:i]+"."+loader.OtherCase]; isSelect {
+					continue
+				}
+			}
+			missing = append(missing, key)
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return fmt.Errorf("%w: %v: %v", ErrMissingKeys, locale, strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
+
+// checkOtherCases returns loader.ErrNoOtherCase if a locale has select cases
+// of a key without its other case, e.g. es.messages.updated.female without
+// es.messages.updated.other. Keys are selects if any locale has their other
+// case, as select cases may be flat keys, e.g. "updated.female", that the
+// loader can't check.
+func checkOtherCases(localizations map[string]string) error {
+	locales := splitLocales(localizations)
+	selects := map[string]bool{}
+	for _, l := range locales {
+		for key := range l {
+			if strings.HasSuffix(key, "."+loader.OtherCase) {
+				selects[strings.TrimSuffix(key, "."+loader.OtherCase)] = true
+			}
+		}
+	}
+
+	var missing []string
+	for locale, l := range locales {
+		for key := range l {
+			i := strings.LastIndexByte(key, '.')
+			if i < 0 || !selects[key[:i]] {
+				continue
+			}
+			if _, ok := l[key[:i]+"."+loader.OtherCase]; !ok {
+				missing = append(missing, locale+"."+key[:i])
+			}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("%w: %v", loader.ErrNoOtherCase, missing[0])
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package generate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/m1/go-localize/loader"
+)
+
+func Test_checkMissingKeys(t *testing.T) {
+	tests := []struct {
+		name          string
+		localizations map[string]string
+		baseLocale    string
+		wantErr       error
+		wantMsg       string
+	}{
+		{
+			name: "every key",
+			localizations: map[string]string{
+				"en.messages.hello": "hello",
+				"es.messages.hello": "hola",
+				"es.messages.extra": "extra",
+			},
+			baseLocale: "en",
+		},
+		{
+			name: "missing keys",
+			localizations: map[string]string{
+				"en.messages.hello": "hello",
+				"en.messages.bye":   "bye",
+				"en.errors.auth":    "denied",
+				"es.messages.hello": "hola",
+			},
+			baseLocale: "en",
+			wantErr:    ErrMissingKeys,
+			wantMsg:    "locale is missing keys of the base locale: es: errors.auth, messages.bye",
+		},
+		{
+			name: "select cases",
+			localizations: map[string]string{
+				"en.messages.items.one":   "one item",
+				"en.messages.items.other": "{{.count}} items",
+				"ja.messages.items.other": "{{.count}} 個",
+			},
+			baseLocale: "en",
+		},
+		{
+			name: "select without other case",
+			localizations: map[string]string{
+				"en.messages.items.one":   "one item",
+				"en.messages.items.other": "{{.count}} items",
+				"es.messages.items.one":   "un artículo",
+			},
+			baseLocale: "en",
+			wantErr:    ErrMissingKeys,
+			wantMsg:    "locale is missing keys of the base locale: es: messages.items.other",
+		},
+		{
+			name: "no base locale localizations",
+			localizations: map[string]string{
+				"es.messages.hello": "hola",
+			},
+			baseLocale: "en",
+			wantErr:    ErrMissingKeys,
+			wantMsg:    "locale is missing keys of the base locale: en has no localizations",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkMissingKeys(tt.localizations, tt.baseLocale)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkMissingKeys() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && err.Error() != tt.wantMsg {
+				t.Errorf("checkMissingKeys() error = %v, want %v", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func Test_checkOtherCases(t *testing.T) {
+	tests := []struct {
+		name          string
+		localizations map[string]string
+		wantErr       error
+		wantMsg       string
+	}{
+		{
+			name: "other cases",
+			localizations: map[string]string{
+				"en.m.updated.female": "she",
+				"en.m.updated.other":  "they",
+				"es.m.updated.other":  "ellos",
+				"es.m.hello.world":    "hola",
+			},
+		},
+		{
+			name: "flat select cases without other",
+			localizations: map[string]string{
+				"en.m.updated.female": "she",
+				"en.m.updated.other":  "they",
+				"es.m.updated":        "ellos",
+				"es.m.updated.female": "ella",
+			},
+			wantErr: loader.ErrNoOtherCase,
+			wantMsg: "select cases have no other case: es.m.updated",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkOtherCases(tt.localizations)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkOtherCases() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && err.Error() != tt.wantMsg {
+				t.Errorf("checkOtherCases() error = %v, want %v", err, tt.wantMsg)
+			}
+		})
+	}
+}
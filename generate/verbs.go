@@ -0,0 +1,154 @@
+package generate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// verbKinds are the kinds of argument each fmt verb formats, verbs of the same
+// kind are interchangeable between locales.
+var verbKinds = map[rune]string{
+	'v': "value",
+	'T': "type",
+	't': "bool",
+	'b': "integer",
+	'c': "integer",
+	'd': "integer",
+	'o': "integer",
+	'O': "integer",
+	'U': "integer",
+	'e': "float",
+	'E': "float",
+	'f': "float",
+	'F': "float",
+	'g': "float",
+	'G': "float",
+	's': "string",
+	'q': "string",
+	'x': "hex",
+	'X': "hex",
+	'p': "pointer",
+}
+
+// checkVerbs returns ErrVerbMismatch if the locales of a key use different
+// numbers or kinds of fmt verbs, so the same Getf arguments work for every
+// locale. Strings using % other than as fmt verbs, e.g. "20 % off", aren't
+// compared.
+func checkVerbs(localizations map[string]string) error {
+	locales := splitLocales(localizations)
+	names := make([]string, 0, len(locales))
+	for locale := range locales {
+		names = append(names, locale)
+	}
+	sort.Strings(names)
+
+	keys := map[string][]string{}
+	for _, locale := range names {
+		for key := range locales[locale] {
+			keys[key] = append(keys[key], locale)
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		first, want := "", ""
+		for _, locale := range keys[key] {
+			got, ok := verbs(locales[locale][key])
+			if !ok {
+				continue
+			}
+			if first == "" {
+				first, want = locale, got
+				continue
+			}
+			if got != want {
+				return fmt.Errorf("%w: %v: %v uses (%v), %v uses (%v)", ErrVerbMismatch, key, first, want, locale, got)
+			}
+		}
+	}
+	return nil
+}
+
+// verbs returns the kinds of the arguments formatted by the fmt verbs in str,
+// in argument order, e.g. "integer, string" for "%[2]s has %[1]d items". It
+// returns false if a % of str isn't a valid fmt verb.
+func verbs(str string) (string, bool) {
+	args := map[int]string{}
+	arg := 0
+	for i := 0; i < len(str); i++ {
+		if str[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(str) && str[i] == '%' {
+			continue
+		}
+
+		// flags
+		for i < len(str) && strings.IndexByte("+-#0", str[i]) >= 0 {
+			i++
+		}
+		// width and precision, either of which can be an argument
+		for _, precision := range []bool{false, true} {
+			if precision {
+				if i >= len(str) || str[i] != '.' {
+					break
+				}
+				i++
+			}
+			i, arg = argIndex(str, i, arg)
+			if i < len(str) && str[i] == '*' {
+				args[arg] = "integer"
+				arg++
+				i++
+				continue
+			}
+			for i < len(str) && str[i] >= '0' && str[i] <= '9' {
+				i++
+			}
+		}
+		i, arg = argIndex(str, i, arg)
+		if i >= len(str) {
+			return "", false
+		}
+		kind, ok := verbKinds[rune(str[i])]
+		if !ok {
+			return "", false
+		}
+		args[arg] = kind
+		arg++
+	}
+
+	indexes := make([]int, 0, len(args))
+	for index := range args {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	kinds := make([]string, len(indexes))
+	for i, index := range indexes {
+		kinds[i] = args[index]
+	}
+	return strings.Join(kinds, ", "), true
+}
+
+// argIndex parses an explicit argument index, e.g. [2], at str[i], returning
+// the index after it and the argument to use next.
+func argIndex(str string, i, arg int) (int, int) {
+	if i >= len(str) || str[i] != '[' {
+		return i, arg
+	}
+	end := strings.IndexByte(str[i:], ']')
+	if end < 0 {
+		return i, arg
+	}
+	var n int
+	if _, err := fmt.Sscanf(str[i+1:i+end], "%d", &n); err != nil || n < 1 {
+		return i + end + 1, arg
+	}
+	return i + end + 1, n - 1
+}
@@ -0,0 +1,146 @@
+package generate
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_checkVerbs(t *testing.T) {
+	tests := []struct {
+		name          string
+		localizations map[string]string
+		wantErr       error
+		wantMsg       string
+	}{
+		{
+			name: "no verbs",
+			localizations: map[string]string{
+				"en.messages.hello": "hello",
+				"es.messages.hello": "hola",
+			},
+		},
+		{
+			name: "same verbs",
+			localizations: map[string]string{
+				"en.messages.items": "%s has %d items",
+				"es.messages.items": "%[2]d artículos de %[1]s",
+				"fr.messages.items": "%q a %d articles",
+			},
+		},
+		{
+			name: "key missing from a locale",
+			localizations: map[string]string{
+				"en.messages.items": "%d items",
+				"es.messages.hello": "hola",
+			},
+		},
+		{
+			name: "different kinds",
+			localizations: map[string]string{
+				"en.messages.items": "%s has %d items",
+				"es.messages.items": "%s tiene %s artículos",
+			},
+			wantErr: ErrVerbMismatch,
+			wantMsg: "locales use different fmt verbs: messages.items: en uses (string, integer), es uses (string, string)",
+		},
+		{
+			name: "percentages",
+			localizations: map[string]string{
+				"en.messages.promo": "Save 20% now",
+				"fr.messages.promo": "Économisez 20 % maintenant",
+				"xx.messages.promo": "Šàvé 20%ñ ñôw",
+			},
+		},
+		{
+			name: "percentage and verbs",
+			localizations: map[string]string{
+				"en.messages.promo": "Save 20% on %d items",
+				"es.messages.promo": "Ahorra un %d%% en %d artículos",
+				"fr.messages.promo": "%s articles",
+			},
+			wantErr: ErrVerbMismatch,
+			wantMsg: "locales use different fmt verbs: messages.promo: es uses (integer, integer), fr uses (string)",
+		},
+		{
+			name: "different number",
+			localizations: map[string]string{
+				"en.messages.items": "%d items",
+				"es.messages.items": "artículos",
+			},
+			wantErr: ErrVerbMismatch,
+			wantMsg: "locales use different fmt verbs: messages.items: en uses (integer), es uses ()",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkVerbs(tt.localizations)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkVerbs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && err.Error() != tt.wantMsg {
+				t.Errorf("checkVerbs() error = %v, want %v", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func Test_verbs(t *testing.T) {
+	tests := []struct {
+		name   string
+		str    string
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "none",
+			str:    "hello {{.name}}",
+			want:   "",
+			wantOk: true,
+		},
+		{
+			name:   "escaped percent",
+			str:    "100%% of %s",
+			want:   "string",
+			wantOk: true,
+		},
+		{
+			name:   "flags width and precision",
+			str:    "%-8s %+.2f %#x %08d",
+			want:   "string, float, hex, integer",
+			wantOk: true,
+		},
+		{
+			name:   "star width and precision",
+			str:    "%*.*f",
+			want:   "integer, integer, float",
+			wantOk: true,
+		},
+		{
+			name:   "explicit indexes",
+			str:    "%[2]s has %[1]d items, %s",
+			want:   "integer, string",
+			wantOk: true,
+		},
+		{
+			name: "trailing percent",
+			str:  "100%",
+		},
+		{
+			name: "space",
+			str:  "100 % of % s",
+		},
+		{
+			name: "unknown verb",
+			str:  "%d items, 20%n off",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := verbs(tt.str)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("verbs() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
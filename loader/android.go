@@ -0,0 +1,282 @@
+package loader
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// androidResources is an Android strings.xml resources file. Values are
+// read as their inner XML, so that their markup, e.g. <b>, is kept.
+type androidResources struct {
+	Strings []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:",innerxml"`
+	} `xml:"string"`
+	Plurals []struct {
+		Name  string `xml:"name,attr"`
+		Items []struct {
+			Quantity string `xml:"quantity,attr"`
+			Value    string `xml:",innerxml"`
+		} `xml:"item"`
+	} `xml:"plurals"`
+	Arrays []struct {
+		Name  string `xml:"name,attr"`
+		Items []struct {
+			Value string `xml:",innerxml"`
+		} `xml:"item"`
+	} `xml:"string-array"`
+}
+
+// parseAndroid parses an Android strings.xml file. Plurals are select cases
+// by quantity, e.g. items.one and items.other, and string arrays are keyed by
+// index, e.g. planets.0.
+func parseAndroid(value []byte, l *localizationFile) error {
+	resources := androidResources{}
+	if err := xml.Unmarshal(value, &resources); err != nil {
+		return err
+	}
+
+	localizations := localizationFile{}
+	add := func(key, inner string) error {
+		text, err := androidText(inner)
+		if err != nil {
+			return fmt.Errorf("%v: %w", key, err)
+		}
+		localizations[key] = printfPlaceholders(text)
+		return nil
+	}
+	for _, s := range resources.Strings {
+		if err := add(s.Name, s.Value); err != nil {
+			return err
+		}
+	}
+	for _, p := range resources.Plurals {
+		hasOther := false
+		for _, item := range p.Items {
+			if err := add(p.Name+"."+item.Quantity, item.Value); err != nil {
+				return err
+			}
+			hasOther = hasOther || item.Quantity == OtherCase
+		}
+		if !hasOther {
+			return fmt.Errorf("%w: %v", ErrNoOtherCase, p.Name)
+		}
+	}
+	for _, a := range resources.Arrays {
+		for i, item := range a.Items {
+			if err := add(a.Name+"."+strconv.Itoa(i), item.Value); err != nil {
+				return err
+			}
+		}
+	}
+	*l = localizations
+	return nil
+}
+
+// parseAndroidMetadata returns the selectors of the plurals of an Android
+// strings.xml file, the replacement of the integer placeholder of their other
+// item, e.g. arg1 for %d.
+func parseAndroidMetadata(value []byte) (map[string]Metadata, error) {
+	resources := androidResources{}
+	if err := xml.Unmarshal(value, &resources); err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]Metadata{}
+	for _, p := range resources.Plurals {
+		for _, item := range p.Items {
+			if item.Quantity != OtherCase {
+				continue
+			}
+			text, err := androidText(item.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %w", p.Name, err)
+			}
+			if selector := printfCount(text); selector != "" {
+				metadata[p.Name] = Metadata{Selector: selector}
+			}
+		}
+	}
+	return metadata, nil
+}
+
+// androidText returns the text of the inner XML of an Android string
+// resource, with its escapes replaced and its white space collapsed outside
+// of double quotes. Its markup, e.g. <b>, is kept, other than the xliff:g
+// elements marking text not to translate, whose content is kept.
+func androidText(inner string) (string, error) {
+	u := &androidUnescaper{}
+	d := xml.NewDecoder(strings.NewReader(inner))
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return u.b.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch token := token.(type) {
+		case xml.CharData:
+			u.text(string(token))
+		case xml.StartElement:
+			if isXLIFF(token.Name) {
+				continue
+			}
+			tag := "<" + xmlName(token.Name)
+			for _, attr := range token.Attr {
+				tag += " " + xmlName(attr.Name) + `="` + xmlAttrEscape(attr.Value) + `"`
+			}
+			u.markup(tag + ">")
+		case xml.EndElement:
+			if !isXLIFF(token.Name) {
+				u.markup("</" + xmlName(token.Name) + ">")
+			}
+		}
+	}
+}
+
+// androidUnescaper builds the text of an Android string resource, keeping
+// whether it is in double quotes and has white space to collapse across the
+// text around markup.
+type androidUnescaper struct {
+	b      strings.Builder
+	quoted bool
+	space  bool
+}
+
+// text adds the text str, replacing its escapes and collapsing its white
+// space outside of double quotes. White space at the start and end of the
+// resource is left out.
+func (u *androidUnescaper) text(str string) {
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		switch {
+		case c == '"':
+			u.quoted = !u.quoted
+			continue
+		case c == '\\' && i+1 < len(str):
+			u.flushSpace()
+			i++
+			switch str[i] {
+			case 'n':
+				u.b.WriteByte('\n')
+			case 't':
+				u.b.WriteByte('\t')
+			case 'u':
+				if r, n, ok := unicodeEscape(str[i+1:]); ok {
+					u.b.WriteRune(r)
+					i += n
+					break
+				}
+				u.b.WriteByte('u')
+			default:
+				u.b.WriteByte(str[i])
+			}
+			continue
+		case !u.quoted && (c == ' ' || c == '\t' || c == '\n' || c == '\r'):
+			u.space = true
+			continue
+		}
+		u.flushSpace()
+		u.b.WriteByte(c)
+	}
+}
+
+// markup adds markup, e.g. <b>, as it is.
+func (u *androidUnescaper) markup(str string) {
+	u.flushSpace()
+	u.b.WriteString(str)
+}
+
+// flushSpace adds the collapsed white space before the text being added, if
+// it isn't at the start of the resource.
+func (u *androidUnescaper) flushSpace() {
+	if u.space && u.b.Len() > 0 {
+		u.b.WriteByte(' ')
+	}
+	u.space = false
+}
+
+// isXLIFF returns whether name is an xliff:g element.
+func isXLIFF(name xml.Name) bool {
+	return name.Local == "g" && (name.Space == "xliff" || name.Space == "urn:oasis:names:tc:xliff:document:1.2")
+}
+
+// xmlName returns name as it is written in XML, prefixed with its namespace's
+// prefix if it has one.
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// xmlAttrEscape escapes str for a double quoted XML attribute value.
+func xmlAttrEscape(str string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;").Replace(str)
+}
+
+// androidLocale returns the locale of an Android values folder, e.g. es for
+// values-es, pt-BR for values-pt-rBR and sr-Latn for values-b+sr+Latn, and
+// empty for the default values folder. Folders with qualifiers other than the
+// locale, e.g. values-night or values-es-v21, aren't for a locale. Other
+// folders are already named after their locale.
+func androidLocale(dir string) (string, bool) {
+	if dir == "values" {
+		return "", true
+	}
+	qualifiers := strings.TrimPrefix(dir, "values-")
+	if qualifiers == dir {
+		return dir, true
+	}
+	if strings.HasPrefix(qualifiers, "b+") {
+		subtags := strings.Split(strings.TrimPrefix(qualifiers, "b+"), "+")
+		return strings.Join(subtags, "-"), isAndroidLanguage(subtags[0])
+	}
+
+	parts := strings.Split(qualifiers, "-")
+	if !isAndroidLanguage(parts[0]) {
+		return "", false
+	}
+	switch {
+	case len(parts) == 1:
+		return parts[0], true
+	case len(parts) == 2 && isAndroidRegion(parts[1]):
+		return parts[0] + "-" + strings.TrimPrefix(parts[1], "r"), true
+	}
+	return "", false
+}
+
+// isAndroidLanguage returns whether a values folder qualifier is a language,
+// two or three lowercase letters, car being the car UI mode.
+func isAndroidLanguage(qualifier string) bool {
+	if len(qualifier) < 2 || len(qualifier) > 3 || qualifier == "car" {
+		return false
+	}
+	for i := 0; i < len(qualifier); i++ {
+		if qualifier[i] < 'a' || qualifier[i] > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// isAndroidRegion returns whether a values folder qualifier is a region, r
+// followed by two uppercase letters or three digits, e.g. rBR or r419.
+func isAndroidRegion(qualifier string) bool {
+	region := strings.TrimPrefix(qualifier, "r")
+	if region == qualifier {
+		return false
+	}
+	switch len(region) {
+	case 2:
+		return region[0] >= 'A' && region[0] <= 'Z' && region[1] >= 'A' && region[1] <= 'Z'
+	case 3:
+		return isDigit(region[0]) && isDigit(region[1]) && isDigit(region[2])
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package loader
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_parseAndroid(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    localizationFile
+		wantErr error
+	}{
+		{
+			name: "strings",
+			value: `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="hello">Hello, %1$s!</string>
+    <string name="quote">Don\'t say \"hi\"\nhere</string>
+    <string name="spaces">  lots   of
+        space "  kept  " </string>
+    <string name="unicode">café &amp; co \u00e9 \uD83D\uDE00</string>
+</resources>`,
+			want: localizationFile{
+				"hello":   "Hello, {{.arg1}}!",
+				"quote":   "Don't say \"hi\"\nhere",
+				"spaces":  "lots of space   kept  ",
+				"unicode": "café & co é \U0001F600",
+			},
+		},
+		{
+			name: "plurals and arrays",
+			value: `<resources>
+    <plurals name="items">
+        <item quantity="one">%d item</item>
+        <item quantity="other">%d items</item>
+    </plurals>
+    <string-array name="planets">
+        <item>Mercury</item>
+        <item>Venus</item>
+    </string-array>
+</resources>`,
+			want: localizationFile{
+				"items.one":   "{{.arg1}} item",
+				"items.other": "{{.arg1}} items",
+				"planets.0":   "Mercury",
+				"planets.1":   "Venus",
+			},
+		},
+		{
+			name: "markup",
+			value: `<resources xmlns:xliff="urn:oasis:names:tc:xliff:document:1.2">
+    <string name="bold">Hello <b>bold</b> world</string>
+    <string name="link">Read <a href="https://example.com/?a=1&amp;b=2">the docs</a> </string>
+    <string name="count">You have <xliff:g id="count">%1$d</xliff:g> <i>new</i> messages</string>
+    <string name="cdata"><![CDATA[<b>raw</b>]]> text</string>
+</resources>`,
+			want: localizationFile{
+				"bold":  "Hello <b>bold</b> world",
+				"link":  `Read <a href="https://example.com/?a=1&amp;b=2">the docs</a>`,
+				"count": "You have {{.arg1}} <i>new</i> messages",
+				"cdata": "<b>raw</b> text",
+			},
+		},
+		{
+			name: "plurals without other",
+			value: `<resources>
+    <plurals name="items"><item quantity="one">%d item</item></plurals>
+</resources>`,
+			wantErr: ErrNoOtherCase,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := localizationFile{}
+			err := parseAndroid([]byte(tt.value), &got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("parseAndroid() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAndroid() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_androidLocale(t *testing.T) {
+	tests := []struct {
+		dir    string
+		want   string
+		wantOk bool
+	}{
+		{dir: "values-es", want: "es", wantOk: true},
+		{dir: "values-pt-rBR", want: "pt-BR", wantOk: true},
+		{dir: "values-es-r419", want: "es-419", wantOk: true},
+		{dir: "values-b+sr+Latn", want: "sr-Latn", wantOk: true},
+		{dir: "en", want: "en", wantOk: true},
+		{dir: "values", want: "", wantOk: true},
+		{dir: "values-night"},
+		{dir: "values-v21"},
+		{dir: "values-land"},
+		{dir: "values-sw600dp"},
+		{dir: "values-car"},
+		{dir: "values-es-night"},
+		{dir: "values-mcc310-es"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			got, ok := androidLocale(tt.dir)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("androidLocale() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// parseStrings parses an Apple .strings file of "key" = "value"; pairs.
+func parseStrings(value []byte, l *localizationFile) error {
+	p := &stringsParser{str: decodeUTF16(value)}
+	localizations := localizationFile{}
+	for {
+		p.skip()
+		if p.done() {
+			break
+		}
+		key, err := p.token()
+		if err != nil {
+			return err
+		}
+		if err := p.expect('='); err != nil {
+			return err
+		}
+		value, err := p.token()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(';'); err != nil {
+			return err
+		}
+		localizations[key] = printfPlaceholders(value)
+	}
+	*l = localizations
+	return nil
+}
+
+// decodeUTF16 returns value as a string, decoding it from UTF-16 if it starts
+// with a UTF-16 byte order mark, as .strings files often are.
+func decodeUTF16(value []byte) string {
+	var bigEndian bool
+	switch {
+	case bytes.HasPrefix(value, []byte{0xfe, 0xff}):
+		bigEndian = true
+	case bytes.HasPrefix(value, []byte{0xff, 0xfe}):
+	default:
+		return string(bytes.TrimPrefix(value, []byte{0xef, 0xbb, 0xbf}))
+	}
+
+	value = value[2:]
+	units := make([]uint16, len(value)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(value[2*i])<<8 | uint16(value[2*i+1])
+		} else {
+			units[i] = uint16(value[2*i+1])<<8 | uint16(value[2*i])
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// stringsParser parses the tokens of a .strings file.
+type stringsParser struct {
+	str  string
+	pos  int
+	line int
+}
+
+func (p *stringsParser) done() bool {
+	return p.pos >= len(p.str)
+}
+
+// skip skips white space and comments.
+func (p *stringsParser) skip() {
+	for !p.done() {
+		switch {
+		case p.str[p.pos] == '\n':
+			p.line++
+			p.pos++
+		case p.str[p.pos] == ' ' || p.str[p.pos] == '\t' || p.str[p.pos] == '\r':
+			p.pos++
+		case strings.HasPrefix(p.str[p.pos:], "//"):
+			end := strings.IndexByte(p.str[p.pos:], '\n')
+			if end < 0 {
+				p.pos = len(p.str)
+				return
+			}
+			p.pos += end
+		case strings.HasPrefix(p.str[p.pos:], "/*"):
+			end := strings.Index(p.str[p.pos:], "*/")
+			if end < 0 {
+				p.pos = len(p.str)
+				return
+			}
+			p.line += strings.Count(p.str[p.pos:p.pos+end], "\n")
+			p.pos += end + len("*/")
+		default:
+			return
+		}
+	}
+}
+
+func (p *stringsParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %v", p.line+1, fmt.Sprintf(format, args...))
+}
+
+func (p *stringsParser) expect(c byte) error {
+	p.skip()
+	if p.done() || p.str[p.pos] != c {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+// token returns the next quoted or unquoted string.
+func (p *stringsParser) token() (string, error) {
+	p.skip()
+	if p.done() {
+		return "", p.errorf("unexpected end of file")
+	}
+	if p.str[p.pos] != '"' {
+		start := p.pos
+		for !p.done() && (isStringsWord(p.str[p.pos])) {
+			p.pos++
+		}
+		if start == p.pos {
+			return "", p.errorf("unexpected %q", p.str[p.pos])
+		}
+		return p.str[start:p.pos], nil
+	}
+
+	b := &strings.Builder{}
+	for p.pos++; !p.done(); p.pos++ {
+		c := p.str[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\n':
+			p.line++
+		case '\\':
+			p.pos++
+			if p.done() {
+				break
+			}
+			switch e := p.str[p.pos]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'U', 'u':
+				if r, n, ok := unicodeEscape(p.str[p.pos+1:]); ok {
+					b.WriteRune(r)
+					p.pos += n
+					continue
+				}
+				b.WriteByte(e)
+			default:
+				b.WriteByte(e)
+			}
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func isStringsWord(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || c == '$' || c == ':' || c == '/' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// errStringsdictFormat is returned for .stringsdict entries with a format
+// other than a single variable, e.g. "%#@items@ in %#@folders@".
+var errStringsdictFormat = errors.New("only formats of a single variable are supported")
+
+// parseStringsdict parses an Apple .stringsdict file. The rule cases of each
+// entry, e.g. one and other, are select cases, with the text around the
+// variable of the entry's format included in every case.
+func parseStringsdict(value []byte, l *localizationFile) error {
+	entries, err := stringsdictEntries(value)
+	if err != nil {
+		return err
+	}
+
+	localizations := localizationFile{}
+	for key, cases := range entries {
+		for c, str := range cases {
+			localizations[key+"."+c] = printfPlaceholders(str)
+		}
+	}
+	*l = localizations
+	return nil
+}
+
+// parseStringsdictMetadata returns the selectors of the entries of an Apple
+// .stringsdict file, the replacement of the integer placeholder of their
+// other case, e.g. arg1 for %d.
+func parseStringsdictMetadata(value []byte) (map[string]Metadata, error) {
+	entries, err := stringsdictEntries(value)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]Metadata{}
+	for key, cases := range entries {
+		if selector := printfCount(cases[OtherCase]); selector != "" {
+			metadata[key] = Metadata{Selector: selector}
+		}
+	}
+	return metadata, nil
+}
+
+// stringsdictEntries returns the rule cases of each entry of an Apple
+// .stringsdict file, with the text around the variable of the entry's format
+// included in every case, before their placeholders are converted.
+func stringsdictEntries(value []byte) (map[string]map[string]string, error) {
+	root, err := decodePlist(xml.NewDecoder(bytes.NewReader(value)))
+	if err != nil {
+		return nil, err
+	}
+	entries, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: the root is not a dict", ErrInvalidValue)
+	}
+
+	cases := map[string]map[string]string{}
+	for key, entry := range entries {
+		dict, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidValue, key)
+		}
+		format, _ := dict["NSStringLocalizedFormatKey"].(string)
+		start, name, end, ok := stringsdictVariable(format)
+		if !ok {
+			return nil, fmt.Errorf("%v: %w", key, errStringsdictFormat)
+		}
+		variable, ok := dict[format[name:end]].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidValue, key)
+		}
+
+		if _, ok := variable[OtherCase]; !ok {
+			return nil, fmt.Errorf("%w: %v", ErrNoOtherCase, key)
+		}
+		cases[key] = map[string]string{}
+		for c, value := range variable {
+			if strings.HasPrefix(c, "NSStringFormat") {
+				continue
+			}
+			str, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %v.%v", ErrInvalidValue, key, c)
+			}
+			cases[key][c] = format[:start] + str + format[end+1:]
+		}
+	}
+	return cases, nil
+}
+
+// stringsdictVariable returns the start of the single variable of a
+// .stringsdict format, e.g. %#@items@ or %2$#@items@, the start and end of its
+// name, and whether the format has a single variable.
+func stringsdictVariable(format string) (int, int, int, bool) {
+	at := strings.Index(format, "#@")
+	if at < 0 || strings.Contains(format[at+len("#@"):], "#@") {
+		return 0, 0, 0, false
+	}
+	start := strings.LastIndexByte(format[:at], '%')
+	if start < 0 {
+		return 0, 0, 0, false
+	}
+	if index := format[start+1 : at]; index != "" {
+		n := strings.TrimSuffix(index, "$")
+		if n == index || n == "" || strings.Trim(n, "0123456789") != "" {
+			return 0, 0, 0, false
+		}
+	}
+	name := at + len("#@")
+	end := strings.IndexByte(format[name:], '@')
+	if end <= 0 {
+		return 0, 0, 0, false
+	}
+	return start, name, name + end, true
+}
+
+// decodePlist decodes the next plist value, a dict as a map, a string or
+// otherwise its text.
+func decodePlist(d *xml.Decoder) (interface{}, error) {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "plist", "array":
+			continue
+		case "dict":
+			dict := map[string]interface{}{}
+			for {
+				var key string
+				token, err := d.Token()
+				if err != nil {
+					return nil, err
+				}
+				if end, ok := token.(xml.EndElement); ok && end.Name.Local == "dict" {
+					return dict, nil
+				}
+				keyStart, ok := token.(xml.StartElement)
+				if !ok {
+					continue
+				}
+				if keyStart.Name.Local != "key" {
+					return nil, fmt.Errorf("expected key, got %v", keyStart.Name.Local)
+				}
+				if err := d.DecodeElement(&key, &keyStart); err != nil {
+					return nil, err
+				}
+				if dict[key], err = decodePlist(d); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			var text string
+			if err := d.DecodeElement(&text, &start); err != nil {
+				return nil, err
+			}
+			return text, nil
+		}
+	}
+}
+
+// appleLocale returns the locale of an Apple .lproj folder, e.g. es for
+// es.lproj and pt-BR for pt_BR.lproj, and empty for the default Base.lproj
+// folder. Other folders are already named after their locale.
+func appleLocale(dir string) (string, bool) {
+	locale := strings.TrimSuffix(dir, ".lproj")
+	if locale == dir {
+		return dir, true
+	}
+	if locale == "Base" {
+		return "", true
+	}
+	return strings.ReplaceAll(locale, "_", "-"), locale != ""
+}
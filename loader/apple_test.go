@@ -0,0 +1,173 @@
+package loader
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+func Test_parseStrings(t *testing.T) {
+	utf16LE := func(str string) string {
+		b := []byte{0xff, 0xfe}
+		for _, u := range utf16.Encode([]rune(str)) {
+			b = append(b, byte(u), byte(u>>8))
+		}
+		return string(b)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    localizationFile
+		wantErr bool
+	}{
+		{
+			name: "strings",
+			value: `/* Greeting */
+"hello" = "Hello, %@!";
+// Escapes
+"quote" = "Say \"hi\"\nthen \U00e9";
+"smile" = "\UD83D\UDE00";
+unquoted_key = "%1$@ has %2$d items";`,
+			want: localizationFile{
+				"hello":        "Hello, {{.arg1}}!",
+				"quote":        "Say \"hi\"\nthen é",
+				"smile":        "\U0001F600",
+				"unquoted_key": "{{.arg1}} has {{.arg2}} items",
+			},
+		},
+		{
+			name:  "utf-16",
+			value: utf16LE(`"hello" = "héllo";`),
+			want:  localizationFile{"hello": "héllo"},
+		},
+		{
+			name:    "missing semicolon",
+			value:   `"hello" = "hello"`,
+			wantErr: true,
+		},
+		{
+			name:    "unterminated string",
+			value:   `"hello" = "hello;`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := localizationFile{}
+			err := parseStrings([]byte(tt.value), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseStrings() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseStrings() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseStringsdict(t *testing.T) {
+	stringsdict := func(format, one, other string) string {
+		return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>items</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>` + format + `</string>
+		<key>count</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>d</string>` + one + other + `
+		</dict>
+	</dict>
+</dict>
+</plist>`
+	}
+	one := "\n\t\t\t<key>one</key>\n\t\t\t<string>%d item</string>"
+	other := "\n\t\t\t<key>other</key>\n\t\t\t<string>%d items</string>"
+
+	tests := []struct {
+		name    string
+		value   string
+		want    localizationFile
+		wantErr error
+	}{
+		{
+			name:  "plural",
+			value: stringsdict("%#@count@", one, other),
+			want: localizationFile{
+				"items.one":   "{{.arg1}} item",
+				"items.other": "{{.arg1}} items",
+			},
+		},
+		{
+			name: "positional variable",
+			value: stringsdict("%2$#@count@",
+				"\n\t\t\t<key>one</key>\n\t\t\t<string>%2$d item in %1$@</string>",
+				"\n\t\t\t<key>other</key>\n\t\t\t<string>%2$d items in %1$@</string>"),
+			want: localizationFile{
+				"items.one":   "{{.arg2}} item in {{.arg1}}",
+				"items.other": "{{.arg2}} items in {{.arg1}}",
+			},
+		},
+		{
+			name:  "format around the variable",
+			value: stringsdict("Found %#@count@.", one, other),
+			want: localizationFile{
+				"items.one":   "Found {{.arg1}} item.",
+				"items.other": "Found {{.arg1}} items.",
+			},
+		},
+		{
+			name:    "no other",
+			value:   stringsdict("%#@count@", one, ""),
+			wantErr: ErrNoOtherCase,
+		},
+		{
+			name:    "several variables",
+			value:   stringsdict("%#@count@ in %#@count@", one, other),
+			wantErr: errStringsdictFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := localizationFile{}
+			err := parseStringsdict([]byte(tt.value), &got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("parseStringsdict() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseStringsdict() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_appleLocale(t *testing.T) {
+	tests := []struct {
+		dir    string
+		want   string
+		wantOk bool
+	}{
+		{dir: "es.lproj", want: "es", wantOk: true},
+		{dir: "pt_BR.lproj", want: "pt-BR", wantOk: true},
+		{dir: "en", want: "en", wantOk: true},
+		{dir: "Base.lproj", want: "", wantOk: true},
+		{dir: ".lproj"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			got, ok := appleLocale(tt.dir)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("appleLocale() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package loader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// arbLocaleKey is the key of the locale of an ARB file, kept in its parsed
+// localizations for File to key them by.
+const arbLocaleKey = "@@locale"
+
+// errICUMessage is returned for ARB messages with more than one plural or
+// select, or with a plural or select nested in another.
+var errICUMessage = errors.New("only messages of a single plural or select are supported")
+
+// Metadata describes a key, from the @key metadata of ARB files or the plurals
+// and select expressions of Android, Apple .stringsdict and Fluent files.
+type Metadata struct {
+	// Description is what the key is for, e.g. for translators.
+	Description string
+	// Placeholders are the replacements the key substitutes, by name.
+	Placeholders map[string]PlaceholderMetadata
+	// Selector is the replacement the select cases of the key are chosen by,
+	// e.g. count for {count, plural, one{...} other{...}}.
+	Selector string
+}
+
+// Merge returns m with the description, placeholders and selector set in
+// other, e.g. of another locale's file, replacing its own.
+func (m Metadata) Merge(other Metadata) Metadata {
+	if other.Description != "" {
+		m.Description = other.Description
+	}
+	if other.Placeholders != nil {
+		placeholders := map[string]PlaceholderMetadata{}
+		for name, p := range m.Placeholders {
+			placeholders[name] = p
+		}
+		for name, p := range other.Placeholders {
+			placeholders[name] = p
+		}
+		m.Placeholders = placeholders
+	}
+	if other.Selector != "" {
+		m.Selector = other.Selector
+	}
+	return m
+}
+
+// PlaceholderMetadata describes a replacement of a key.
+type PlaceholderMetadata struct {
+	// Type is the type of the replacement, e.g. String, int or DateTime.
+	Type string
+	// Example is an example value of the replacement.
+	Example string
+	// Format is how the replacement is formatted, e.g. compact or yMd.
+	Format string
+}
+
+// arbMetadata is the @key metadata of a message in an ARB file.
+type arbMetadata struct {
+	Description  string `json:"description"`
+	Placeholders map[string]struct {
+		Type    string      `json:"type"`
+		Example interface{} `json:"example"`
+		Format  string      `json:"format"`
+	} `json:"placeholders"`
+}
+
+// parseARB parses a Flutter ARB file. ICU placeholders are substitutions, e.g.
+// {name} is {{.name}}, and the cases of a plural or select are select cases,
+// e.g. {count, plural, =0{none} one{one item} other{{count} items}} is
+// items.0, items.one and items.other. The @key metadata is loaded by
+// FileMetadata.
+func parseARB(value []byte, l *localizationFile) error {
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(value, &raw); err != nil {
+		return err
+	}
+
+	localizations := localizationFile{}
+	for key, value := range raw {
+		if key == arbLocaleKey {
+			if locale, ok := value.(string); ok {
+				localizations[arbLocaleKey] = locale
+			}
+			continue
+		}
+		if strings.HasPrefix(key, "@") {
+			continue
+		}
+
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%w: %v", ErrInvalidValue, key)
+		}
+		message, err := parseICU(str)
+		if err != nil {
+			return fmt.Errorf("%v: %w", key, err)
+		}
+		if message.cases == nil {
+			localizations[key] = message.text
+			continue
+		}
+		if _, ok := message.cases[OtherCase]; !ok {
+			return fmt.Errorf("%w: %v", ErrNoOtherCase, key)
+		}
+		for c, text := range message.cases {
+			localizations[key+"."+c] = text
+		}
+	}
+	*l = localizations
+	return nil
+}
+
+// parseARBMetadata returns the metadata of the messages of an ARB file, by
+// message key.
+func parseARBMetadata(value []byte) (map[string]Metadata, error) {
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(value, &raw); err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]Metadata{}
+	for key, value := range raw {
+		if strings.HasPrefix(key, "@") {
+			continue
+		}
+
+		m := Metadata{}
+		if meta, ok := raw["@"+key]; ok {
+			a := arbMetadata{}
+			if err := json.Unmarshal(meta, &a); err != nil {
+				return nil, fmt.Errorf("@%v: %w", key, err)
+			}
+			m.Description = a.Description
+			for name, p := range a.Placeholders {
+				if m.Placeholders == nil {
+					m.Placeholders = map[string]PlaceholderMetadata{}
+				}
+				example := ""
+				if p.Example != nil {
+					example = fmt.Sprint(p.Example)
+				}
+				m.Placeholders[name] = PlaceholderMetadata{Type: p.Type, Example: example, Format: p.Format}
+			}
+		}
+
+		var str string
+		if err := json.Unmarshal(value, &str); err == nil {
+			if message, err := parseICU(str); err == nil {
+				m.Selector = message.selector
+			}
+		}
+		if m.Description != "" || m.Placeholders != nil || m.Selector != "" {
+			metadata[key] = m
+		}
+	}
+	return metadata, nil
+}
+
+// icuMessage is a parsed ICU message, either text or the cases of a plural
+// or select chosen by the selector, the text around it included in every
+// case.
+type icuMessage struct {
+	text     string
+	selector string
+	cases    map[string]string
+}
+
+// parseICU parses the subset of ICU message format ARB files use: {name}
+// placeholders and a single plural, selectordinal or select.
+func parseICU(str string) (icuMessage, error) {
+	message := icuMessage{}
+	var prefix, suffix strings.Builder
+	text := &prefix
+	for i := 0; i < len(str); i++ {
+		if str[i] != '{' {
+			text.WriteByte(str[i])
+			continue
+		}
+		end, err := icuArgumentEnd(str, i)
+		if err != nil {
+			return message, err
+		}
+
+		name, kind, rest := icuArgument(str[i+1 : end])
+		switch kind {
+		case "":
+			text.WriteString("{{." + name + "}}")
+		case "plural", "selectordinal", "select":
+			if message.cases != nil {
+				return message, errICUMessage
+			}
+			cases, err := icuCases(rest)
+			if err != nil {
+				return message, err
+			}
+			message.selector, message.cases = name, cases
+			text = &suffix
+		default:
+			// Formatted arguments, e.g. {amount, number}, are substituted
+			// unformatted.
+			text.WriteString("{{." + name + "}}")
+		}
+		i = end
+	}
+
+	if message.cases == nil {
+		message.text = prefix.String()
+		return message, nil
+	}
+	for c, text := range message.cases {
+		message.cases[c] = prefix.String() + text + suffix.String()
+	}
+	return message, nil
+}
+
+// icuArgumentEnd returns the index of the } closing the argument opened at
+// start.
+func icuArgumentEnd(str string, start int) (int, error) {
+	depth := 0
+	for i := start; i < len(str); i++ {
+		switch str[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unterminated argument %q", str[start:])
+}
+
+// icuArgument splits an argument into its name, its kind, e.g. plural, and
+// the rest, e.g. its cases.
+func icuArgument(arg string) (string, string, string) {
+	parts := strings.SplitN(arg, ",", 3)
+	name := strings.TrimSpace(parts[0])
+	if len(parts) == 1 {
+		return name, "", ""
+	}
+	kind := strings.TrimSpace(parts[1])
+	if len(parts) == 2 {
+		return name, kind, ""
+	}
+	return name, kind, parts[2]
+}
+
+// icuCases parses the cases of a plural or select, e.g. =0{none} other{...}.
+// Exact matches like =0 are keyed by their number.
+func icuCases(str string) (map[string]string, error) {
+	cases := map[string]string{}
+	for i := 0; i < len(str); {
+		if str[i] == ' ' || str[i] == '\t' || str[i] == '\n' {
+			i++
+			continue
+		}
+		open := strings.IndexByte(str[i:], '{')
+		if open < 0 {
+			return nil, fmt.Errorf("case %q has no message", strings.TrimSpace(str[i:]))
+		}
+		open += i
+		end, err := icuArgumentEnd(str, open)
+		if err != nil {
+			return nil, err
+		}
+
+		c := strings.TrimPrefix(strings.TrimSpace(str[i:open]), "=")
+		if strings.HasPrefix(c, "offset:") {
+			return nil, fmt.Errorf("plural offsets are not supported")
+		}
+		message, err := parseICU(str[open+1 : end])
+		if err != nil {
+			return nil, err
+		}
+		if message.cases != nil {
+			return nil, errICUMessage
+		}
+		cases[c] = message.text
+		i = end + 1
+	}
+	return cases, nil
+}
+
+// FileMetadata loads the metadata of the keys in file, keyed like File
+// without the locale, e.g. root/app_en.arb's @hello is app.hello. Files
+// without metadata, e.g. JSON files, have none.
+func FileMetadata(fsys fs.FS, root, file string) (map[string]Metadata, error) {
+	return Options{}.FileMetadata(fsys, root, file)
+}
+
+// FileMetadata loads the metadata of the keys in file, like FileMetadata.
+func (o Options) FileMetadata(fsys fs.FS, root, file string) (map[string]Metadata, error) {
+	ext := filepath.Ext(file)
+	parseMetadata, ok := metadataParsers[ext]
+	if !ok {
+		return nil, nil
+	}
+	byteValue, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := parseMetadata(byteValue)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", file, err)
+	}
+	l := localizationFile{}
+	if err := parsers[ext](byteValue, &l); err != nil {
+		return nil, fmt.Errorf("%v: %w", file, err)
+	}
+	slicePath, err := o.keyPath(root, file, l)
+	if err != nil || slicePath == nil {
+		return nil, err
+	}
+
+	newMetadata := map[string]Metadata{}
+	for key, m := range metadata {
+		newMetadata[strings.Join(append(slicePath[1:], key), ".")] = m
+	}
+	return newMetadata, nil
+}
+
+// LoadMetadata loads the metadata of the keys of every translation file in
+// the root folder of fsys, keyed without the locale, e.g. app.hello.
+func LoadMetadata(fsys fs.FS, root string) (map[string]Metadata, error) {
+	return Options{}.LoadMetadata(fsys, root)
+}
+
+// LoadMetadata loads the metadata of the keys of every translation file in
+// the root folder of fsys, like LoadMetadata.
+func (o Options) LoadMetadata(fsys fs.FS, root string) (map[string]Metadata, error) {
+	files, err := Files(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]Metadata{}
+	for _, file := range files {
+		newMetadata, err := o.FileMetadata(fsys, root, file)
+		if err != nil {
+			return nil, err
+		}
+		for key, m := range newMetadata {
+			metadata[key] = metadata[key].Merge(m)
+		}
+	}
+	return metadata, nil
+}
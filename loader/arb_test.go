@@ -0,0 +1,180 @@
+package loader
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func Test_parseARB(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    localizationFile
+		wantErr error
+	}{
+		{
+			name: "messages",
+			value: `{
+  "@@locale": "pt_BR",
+  "@@last_modified": "2021-01-01",
+  "hello": "Hello {name}!",
+  "@hello": {"description": "Greeting", "placeholders": {"name": {"type": "String"}}},
+  "total": "Total: {amount, number, currency}"
+}`,
+			want: localizationFile{
+				arbLocaleKey: "pt_BR",
+				"hello":      "Hello {{.name}}!",
+				"total":      "Total: {{.amount}}",
+			},
+		},
+		{
+			name:  "plural",
+			value: `{"items": "You have {count, plural, =0{no items} one{one item} other{{count} items}}."}`,
+			want: localizationFile{
+				"items.0":     "You have no items.",
+				"items.one":   "You have one item.",
+				"items.other": "You have {{.count}} items.",
+			},
+		},
+		{
+			name:  "select",
+			value: `{"updated": "{gender, select, female{{user} updated her profile} other{{user} updated their profile}}"}`,
+			want: localizationFile{
+				"updated.female": "{{.user}} updated her profile",
+				"updated.other":  "{{.user}} updated their profile",
+			},
+		},
+		{
+			name:    "no other",
+			value:   `{"items": "{count, plural, one{one item}}"}`,
+			wantErr: ErrNoOtherCase,
+		},
+		{
+			name:    "nested plural",
+			value:   `{"items": "{count, plural, other{{gender, select, other{items}}}}"}`,
+			wantErr: errICUMessage,
+		},
+		{
+			name:    "not a string",
+			value:   `{"items": 1}`,
+			wantErr: ErrInvalidValue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := localizationFile{}
+			err := parseARB([]byte(tt.value), &got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("parseARB() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseARB() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseICU(t *testing.T) {
+	tests := []struct {
+		name    string
+		str     string
+		wantErr bool
+	}{
+		{name: "unterminated", str: "Hello {name", wantErr: true},
+		{name: "case without message", str: "{count, plural, other}", wantErr: true},
+		{name: "offset", str: "{count, plural, offset:1 other{items}}", wantErr: true},
+		{name: "two plurals", str: "{a, plural, other{a}} {b, plural, other{b}}", wantErr: true},
+		{name: "valid", str: "{a, plural, other{a}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseICU(tt.str); (err != nil) != tt.wantErr {
+				t.Errorf("parseICU() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadMetadata(t *testing.T) {
+	fsys := fstest.MapFS{
+		"l10n/app_en.arb": {Data: []byte(`{
+  "hello": "Hello {name}!",
+  "@hello": {
+    "description": "Greeting on the home page",
+    "placeholders": {"name": {"type": "String", "example": "Bob"}}
+  },
+  "items": "{count, plural, one{one item} other{{count} items}}",
+  "@items": {"placeholders": {"count": {"type": "int", "format": "compact"}}},
+  "bye": "Bye"
+}`)},
+		"l10n/app_es.arb": {Data: []byte(`{
+  "hello": "¡Hola {name}!",
+  "items": "{count, plural, one{un artículo} other{{count} artículos}}"
+}`)},
+		"l10n/en/messages.json": {Data: []byte(`{"hello": "hello"}`)},
+	}
+
+	got, err := LoadMetadata(fsys, "l10n")
+	if err != nil {
+		t.Fatalf("LoadMetadata() error = %v", err)
+	}
+	want := map[string]Metadata{
+		"app.hello": {
+			Description:  "Greeting on the home page",
+			Placeholders: map[string]PlaceholderMetadata{"name": {Type: "String", Example: "Bob"}},
+		},
+		"app.items": {
+			Placeholders: map[string]PlaceholderMetadata{"count": {Type: "int", Format: "compact"}},
+			Selector:     "count",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadMetadata() got = %v, want %v", got, want)
+	}
+}
+
+func TestLoadMetadata_selectors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"res/values-en/strings.xml": {Data: []byte(`<resources>
+    <plurals name="items">
+        <item quantity="one">%1$s has one item</item>
+        <item quantity="other">%1$s has %2$d items</item>
+    </plurals>
+    <plurals name="names"><item quantity="other">%s</item></plurals>
+</resources>`)},
+		"res/en.lproj/Localizable.stringsdict": {Data: []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>files</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%#@count@</string>
+		<key>count</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>other</key>
+			<string>%d files</string>
+		</dict>
+	</dict>
+</dict>
+</plist>`)},
+		"res/en/main.ftl": {Data: []byte("emails = { $unread-count ->\n    [one] One email\n   *[other] { $unread-count } emails\n}\n")},
+	}
+
+	got, err := LoadMetadata(fsys, "res")
+	if err != nil {
+		t.Fatalf("LoadMetadata() error = %v", err)
+	}
+	want := map[string]Metadata{
+		"strings.items":     {Selector: "arg2"},
+		"Localizable.files": {Selector: "arg1"},
+		"main.emails":       {Selector: "unread_count"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadMetadata() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,709 @@
+package loader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseFluent parses a Mozilla Fluent .ftl file. Messages are keyed by their
+// identifier and their attributes by the identifier and the attribute, e.g.
+// login.placeholder. Variables are substitutions, e.g. { $name } is {{.name}},
+// with hyphens replaced by underscores, and references to the messages and
+// terms of the file are inlined. The variants of a select expression are
+// select cases, e.g. emails.one and emails.other, the default variant also
+// being the other case. Errors are reported with their line and column.
+func parseFluent(value []byte, l *localizationFile) error {
+	values, err := fluentValues(value)
+	if err != nil {
+		return err
+	}
+
+	localizations := localizationFile{}
+	for key, v := range values {
+		if v.cases == nil {
+			localizations[key] = v.text
+			continue
+		}
+		for c, text := range v.cases {
+			localizations[key+"."+c] = text
+		}
+	}
+	*l = localizations
+	return nil
+}
+
+// parseFluentMetadata returns the selectors of the messages and attributes of
+// a Mozilla Fluent .ftl file with a select expression, the replacement of its
+// variable, e.g. count for { $count -> ... }.
+func parseFluentMetadata(value []byte) (map[string]Metadata, error) {
+	values, err := fluentValues(value)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]Metadata{}
+	for key, v := range values {
+		if v.selector != "" {
+			metadata[key] = Metadata{Selector: v.selector}
+		}
+	}
+	return metadata, nil
+}
+
+// fluentValues returns the resolved values of the messages and attributes of
+// a Mozilla Fluent .ftl file, by key.
+func fluentValues(value []byte) (map[string]ftlValue, error) {
+	p := &ftlParser{src: strings.ReplaceAll(string(value), "\r\n", "\n")}
+	entries, ids, err := p.resource()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ftlResolver{parser: p, entries: entries, visiting: map[string]bool{}}
+	values := map[string]ftlValue{}
+	add := func(key string, pattern ftlPattern) error {
+		v, err := r.resolve(pattern)
+		if err != nil {
+			return err
+		}
+		values[key] = v
+		return nil
+	}
+	for _, id := range ids {
+		e := entries[id]
+		if strings.HasPrefix(id, "-") {
+			continue
+		}
+		if e.value != nil {
+			if err := add(id, e.value); err != nil {
+				return nil, err
+			}
+		}
+		for _, attr := range e.attrOrder {
+			if err := add(id+"."+attr, e.attrs[attr]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return values, nil
+}
+
+// ftlEntry is a message or term, terms' identifiers starting with -.
+type ftlEntry struct {
+	pos       int
+	value     ftlPattern
+	attrs     map[string]ftlPattern
+	attrOrder []string
+}
+
+// ftlPattern is the text and placeables of a value.
+type ftlPattern []ftlNode
+
+// ftlNode is text, a variable, a reference to a message or term and its
+// attribute, or a select expression.
+type ftlNode struct {
+	pos      int
+	text     string
+	variable string
+	ref      string
+	attr     string
+	selector *ftlNode
+	variants []ftlVariant
+	def      int
+}
+
+// ftlVariant is a variant of a select expression.
+type ftlVariant struct {
+	pos   int
+	key   string
+	value ftlPattern
+}
+
+// ftlParser parses the syntax of a .ftl file.
+type ftlParser struct {
+	src string
+	pos int
+}
+
+// errorf returns an error at pos, prefixed with its line and column.
+func (p *ftlParser) errorf(pos int, format string, args ...interface{}) error {
+	lineStart := strings.LastIndexByte(p.src[:pos], '\n') + 1
+	line := strings.Count(p.src[:pos], "\n") + 1
+	column := utf8.RuneCountInString(p.src[lineStart:pos]) + 1
+	return fmt.Errorf("%d:%d: %v", line, column, fmt.Sprintf(format, args...))
+}
+
+func (p *ftlParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *ftlParser) skipInline() {
+	for p.peek() == ' ' {
+		p.pos++
+	}
+}
+
+func (p *ftlParser) skipBlank() {
+	for p.peek() == ' ' || p.peek() == '\n' {
+		p.pos++
+	}
+}
+
+func (p *ftlParser) expect(c byte) error {
+	if p.peek() != c {
+		return p.errorf(p.pos, "expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+// resource parses the entries of the file, returning them by identifier and
+// the identifiers in order.
+func (p *ftlParser) resource() (map[string]*ftlEntry, []string, error) {
+	entries := map[string]*ftlEntry{}
+	var ids []string
+	for p.pos < len(p.src) {
+		switch c := p.peek(); {
+		case c == '\n':
+			p.pos++
+		case c == ' ':
+			p.skipInline()
+			if p.peek() != '\n' && p.peek() != 0 {
+				return nil, nil, p.errorf(p.pos, "expected a message, term or comment at the start of the line")
+			}
+		case c == '#':
+			for p.peek() != '\n' && p.peek() != 0 {
+				p.pos++
+			}
+		case c == '-' || isFTLIdentifierStart(c):
+			start := p.pos
+			id, e, err := p.entry()
+			if err != nil {
+				return nil, nil, err
+			}
+			if _, ok := entries[id]; ok {
+				return nil, nil, p.errorf(start, "duplicate %v", id)
+			}
+			entries[id] = e
+			ids = append(ids, id)
+		default:
+			return nil, nil, p.errorf(p.pos, "expected a message, term or comment")
+		}
+	}
+	return entries, ids, nil
+}
+
+// entry parses a message or term and its attributes.
+func (p *ftlParser) entry() (string, *ftlEntry, error) {
+	e := &ftlEntry{pos: p.pos, attrs: map[string]ftlPattern{}}
+	term := p.peek() == '-'
+	if term {
+		p.pos++
+	}
+	id, err := p.identifier()
+	if err != nil {
+		return "", nil, err
+	}
+	if term {
+		id = "-" + id
+	}
+	p.skipInline()
+	if err := p.expect('='); err != nil {
+		return "", nil, err
+	}
+	if e.value, err = p.pattern(); err != nil {
+		return "", nil, err
+	}
+
+	for {
+		lineEnd := p.pos
+		p.skipBlank()
+		if p.pos == lineEnd || p.peek() != '.' || p.src[p.pos-1] != ' ' {
+			p.pos = lineEnd
+			break
+		}
+		p.pos++
+		attrPos := p.pos
+		attr, err := p.identifier()
+		if err != nil {
+			return "", nil, err
+		}
+		p.skipInline()
+		if err := p.expect('='); err != nil {
+			return "", nil, err
+		}
+		value, err := p.pattern()
+		if err != nil {
+			return "", nil, err
+		}
+		if value == nil {
+			return "", nil, p.errorf(attrPos, "attribute %v has no value", attr)
+		}
+		if _, ok := e.attrs[attr]; ok {
+			return "", nil, p.errorf(attrPos, "duplicate attribute %v", attr)
+		}
+		e.attrs[attr] = value
+		e.attrOrder = append(e.attrOrder, attr)
+	}
+
+	if e.value == nil && (term || len(e.attrs) == 0) {
+		return "", nil, p.errorf(e.pos, "%v has no value", id)
+	}
+	return id, e, nil
+}
+
+// identifier parses an identifier, e.g. login-input.
+func (p *ftlParser) identifier() (string, error) {
+	start := p.pos
+	if !isFTLIdentifierStart(p.peek()) {
+		return "", p.errorf(p.pos, "expected an identifier")
+	}
+	for isFTLIdentifierStart(p.peek()) || isDigit(p.peek()) || p.peek() == '-' || p.peek() == '_' {
+		p.pos++
+	}
+	return p.src[start:p.pos], nil
+}
+
+// ftlLine is a line of a pattern, after the blank lines before it.
+type ftlLine struct {
+	blank  int
+	indent int
+	nodes  ftlPattern
+}
+
+// pattern parses the text and placeables of a value, up to the end of its
+// last line. The lines after the first are block lines, which must be
+// indented and are joined with new lines, their common indentation removed.
+// Patterns without any text are nil.
+func (p *ftlParser) pattern() (ftlPattern, error) {
+	var lines []ftlLine
+	p.skipInline()
+	current := ftlLine{}
+	for {
+		switch p.peek() {
+		case '{':
+			node, err := p.placeable()
+			if err != nil {
+				return nil, err
+			}
+			current.nodes = append(current.nodes, node)
+			continue
+		case '}':
+			return nil, p.errorf(p.pos, "unbalanced closing brace")
+		case '\n', 0:
+			lines = append(lines, current)
+			next, ok := p.blockLine()
+			if !ok {
+				return joinFTLLines(lines), nil
+			}
+			current = next
+			continue
+		}
+
+		start := p.pos
+		for p.pos < len(p.src) && strings.IndexByte("{}\n", p.src[p.pos]) < 0 {
+			p.pos++
+		}
+		current.nodes = append(current.nodes, ftlNode{pos: start, text: p.src[start:p.pos]})
+	}
+}
+
+// blockLine moves to the start of the text of the next block line of a
+// pattern, if there is one, returning the line without its nodes.
+func (p *ftlParser) blockLine() (ftlLine, bool) {
+	next := ftlLine{}
+	for look := p.pos; look < len(p.src); {
+		look++
+		start := look
+		for look < len(p.src) && p.src[look] == ' ' {
+			look++
+		}
+		if look < len(p.src) && p.src[look] == '\n' {
+			next.blank++
+			continue
+		}
+		if look == len(p.src) || look == start || strings.IndexByte("[*.}", p.src[look]) >= 0 {
+			return next, false
+		}
+		next.indent = look - start
+		p.pos = look
+		return next, true
+	}
+	return next, false
+}
+
+// joinFTLLines joins the lines of a pattern, removing the indentation common
+// to the block lines and the white space at the end of the pattern.
+func joinFTLLines(lines []ftlLine) ftlPattern {
+	common := -1
+	for _, l := range lines[1:] {
+		if common < 0 || l.indent < common {
+			common = l.indent
+		}
+	}
+
+	var pattern ftlPattern
+	for i, l := range lines {
+		if i > 0 && pattern != nil {
+			text := strings.Repeat("\n", l.blank+1) + strings.Repeat(" ", l.indent-common)
+			pattern = append(pattern, ftlNode{text: text})
+		} else if i > 0 && l.indent > common {
+			pattern = append(pattern, ftlNode{text: strings.Repeat(" ", l.indent-common)})
+		}
+		pattern = append(pattern, l.nodes...)
+	}
+
+	// The white space at the end of the pattern is not part of it.
+	for len(pattern) > 0 {
+		last := &pattern[len(pattern)-1]
+		if last.selector != nil || last.variable != "" || last.ref != "" {
+			break
+		}
+		last.text = strings.TrimRight(last.text, " \n")
+		if last.text != "" {
+			break
+		}
+		pattern = pattern[:len(pattern)-1]
+	}
+	return pattern
+}
+
+// placeable parses a placeable, e.g. { $name } or a select expression.
+func (p *ftlParser) placeable() (ftlNode, error) {
+	start := p.pos
+	p.pos++
+	p.skipBlank()
+	node, err := p.expression()
+	if err != nil {
+		return node, err
+	}
+	p.skipBlank()
+	if strings.HasPrefix(p.src[p.pos:], "->") {
+		p.pos += len("->")
+		selector := node
+		node = ftlNode{pos: start, selector: &selector}
+		if node.variants, node.def, err = p.variants(start); err != nil {
+			return node, err
+		}
+		p.skipBlank()
+	}
+	if err := p.expect('}'); err != nil {
+		return node, err
+	}
+	return node, nil
+}
+
+// expression parses an inline expression: a string or number literal, a
+// variable, a reference to a message or term or one of their attributes, a
+// NUMBER or DATETIME call of a variable, or a nested placeable.
+func (p *ftlParser) expression() (ftlNode, error) {
+	start := p.pos
+	switch c := p.peek(); {
+	case c == '"':
+		text, err := p.stringLiteral()
+		return ftlNode{pos: start, text: text}, err
+	case isDigit(c) || (c == '-' && p.pos+1 < len(p.src) && isDigit(p.src[p.pos+1])):
+		p.pos++
+		for isDigit(p.peek()) || p.peek() == '.' {
+			p.pos++
+		}
+		return ftlNode{pos: start, text: p.src[start:p.pos]}, nil
+	case c == '$':
+		p.pos++
+		id, err := p.identifier()
+		return ftlNode{pos: start, variable: id}, err
+	case c == '{':
+		return p.placeable()
+	case c == '-' || isFTLIdentifierStart(c):
+		term := c == '-'
+		if term {
+			p.pos++
+		}
+		id, err := p.identifier()
+		if err != nil {
+			return ftlNode{}, err
+		}
+		if p.peek() == '(' {
+			if term {
+				return ftlNode{}, p.errorf(p.pos, "term arguments are not supported")
+			}
+			return p.call(start, id)
+		}
+		node := ftlNode{pos: start, ref: id}
+		if term {
+			node.ref = "-" + id
+		}
+		if p.peek() == '.' {
+			p.pos++
+			if node.attr, err = p.identifier(); err != nil {
+				return ftlNode{}, err
+			}
+		}
+		return node, nil
+	}
+	return ftlNode{}, p.errorf(p.pos, "expected an expression")
+}
+
+// call parses the arguments of a NUMBER or DATETIME call, whose variable is
+// substituted unformatted.
+func (p *ftlParser) call(start int, function string) (ftlNode, error) {
+	if function != "NUMBER" && function != "DATETIME" {
+		return ftlNode{}, p.errorf(start, "function %v is not supported", function)
+	}
+	p.pos++
+	p.skipBlank()
+	if p.peek() != '$' {
+		return ftlNode{}, p.errorf(p.pos, "expected a variable")
+	}
+	node, err := p.expression()
+	if err != nil {
+		return node, err
+	}
+	for p.peek() != ')' {
+		if p.peek() == 0 {
+			return node, p.errorf(start, "unterminated call of %v", function)
+		}
+		p.pos++
+	}
+	p.pos++
+	return node, nil
+}
+
+// stringLiteral parses a string literal, e.g. "{" or "\u00E9".
+func (p *ftlParser) stringLiteral() (string, error) {
+	start := p.pos
+	b := &strings.Builder{}
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		switch c := p.src[p.pos]; c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\n':
+			return "", p.errorf(start, "unterminated string literal")
+		case '\\':
+			p.pos++
+			n := 0
+			switch p.peek() {
+			case '"', '\\':
+				b.WriteByte(p.src[p.pos])
+				continue
+			case 'u':
+				n = 4
+			case 'U':
+				n = 6
+			default:
+				return "", p.errorf(p.pos-1, "unknown escape sequence")
+			}
+			if p.pos+n >= len(p.src) {
+				return "", p.errorf(p.pos-1, "malformed unicode escape sequence")
+			}
+			r, err := strconv.ParseUint(p.src[p.pos+1:p.pos+1+n], 16, 32)
+			if err != nil {
+				return "", p.errorf(p.pos-1, "malformed unicode escape sequence")
+			}
+			b.WriteRune(rune(r))
+			p.pos += n
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf(start, "unterminated string literal")
+}
+
+// variants parses the variants of the select expression at start, returning
+// the index of the default variant.
+func (p *ftlParser) variants(start int) ([]ftlVariant, int, error) {
+	var variants []ftlVariant
+	def := -1
+	for {
+		p.skipBlank()
+		pos := p.pos
+		if p.peek() == '}' || p.peek() == 0 {
+			break
+		}
+		if p.peek() == '*' {
+			if def >= 0 {
+				return nil, 0, p.errorf(pos, "more than one default variant")
+			}
+			def = len(variants)
+			p.pos++
+		}
+		if err := p.expect('['); err != nil {
+			return nil, 0, err
+		}
+		p.skipBlank()
+		keyStart := p.pos
+		for p.pos < len(p.src) && strings.IndexByte("] \n", p.src[p.pos]) < 0 {
+			p.pos++
+		}
+		key := p.src[keyStart:p.pos]
+		if key == "" {
+			return nil, 0, p.errorf(keyStart, "expected a variant key")
+		}
+		p.skipBlank()
+		if err := p.expect(']'); err != nil {
+			return nil, 0, err
+		}
+		value, err := p.pattern()
+		if err != nil {
+			return nil, 0, err
+		}
+		variants = append(variants, ftlVariant{pos: pos, key: key, value: value})
+	}
+	if def < 0 {
+		return nil, 0, p.errorf(start, "select expression has no default variant")
+	}
+	return variants, def, nil
+}
+
+// ftlValue is a resolved pattern, either text or the cases of its select
+// expression, the text around it included in every case, and the replacement
+// of the select expression's variable.
+type ftlValue struct {
+	text     string
+	cases    map[string]string
+	selector string
+}
+
+// ftlResolver resolves patterns, inlining the messages and terms they
+// reference.
+type ftlResolver struct {
+	parser   *ftlParser
+	entries  map[string]*ftlEntry
+	visiting map[string]bool
+}
+
+func (r *ftlResolver) resolve(pattern ftlPattern) (ftlValue, error) {
+	var prefix, suffix strings.Builder
+	text := &prefix
+	var cases map[string]string
+	selector := ""
+	addCases := func(pos int, v ftlValue) error {
+		if cases != nil {
+			return r.parser.errorf(pos, "only one select expression per pattern is supported")
+		}
+		cases, selector, text = v.cases, v.selector, &suffix
+		return nil
+	}
+
+	for _, node := range pattern {
+		switch {
+		case node.selector != nil:
+			v, err := r.selectExpression(node)
+			if err != nil {
+				return ftlValue{}, err
+			}
+			if v.cases == nil {
+				text.WriteString(v.text)
+				continue
+			}
+			if err := addCases(node.pos, v); err != nil {
+				return ftlValue{}, err
+			}
+		case node.variable != "":
+			text.WriteString("{{." + strings.ReplaceAll(node.variable, "-", "_") + "}}")
+		case node.ref != "":
+			v, err := r.reference(node)
+			if err != nil {
+				return ftlValue{}, err
+			}
+			if v.cases == nil {
+				text.WriteString(v.text)
+				continue
+			}
+			if err := addCases(node.pos, v); err != nil {
+				return ftlValue{}, err
+			}
+		default:
+			text.WriteString(node.text)
+		}
+	}
+
+	if cases == nil {
+		return ftlValue{text: prefix.String()}, nil
+	}
+	v := ftlValue{cases: map[string]string{}, selector: selector}
+	for c, text := range cases {
+		v.cases[c] = prefix.String() + text + suffix.String()
+	}
+	return v, nil
+}
+
+// reference resolves the message, term or attribute node references.
+func (r *ftlResolver) reference(node ftlNode) (ftlValue, error) {
+	kind := "message"
+	if strings.HasPrefix(node.ref, "-") {
+		kind = "term"
+	}
+	e, ok := r.entries[node.ref]
+	if !ok {
+		return ftlValue{}, r.parser.errorf(node.pos, "unknown %v %v", kind, node.ref)
+	}
+	pattern, name := e.value, node.ref
+	if node.attr != "" {
+		pattern, name = e.attrs[node.attr], node.ref+"."+node.attr
+	}
+	if pattern == nil {
+		return ftlValue{}, r.parser.errorf(node.pos, "%v has no value", name)
+	}
+	if r.visiting[name] {
+		return ftlValue{}, r.parser.errorf(node.pos, "%v references itself", name)
+	}
+
+	r.visiting[name] = true
+	defer delete(r.visiting, name)
+	return r.resolve(pattern)
+}
+
+// selectExpression resolves a select expression. The variants of a variable
+// selector are select cases, and a term attribute selector picks its
+// variant, e.g. { -brand.gender -> ... }.
+func (r *ftlResolver) selectExpression(node ftlNode) (ftlValue, error) {
+	selector := node.selector
+	switch {
+	case selector.variable != "":
+	case strings.HasPrefix(selector.ref, "-") && selector.attr != "":
+		v, err := r.reference(*selector)
+		if err != nil {
+			return ftlValue{}, err
+		}
+		variant := node.variants[node.def]
+		for _, candidate := range node.variants {
+			if candidate.key == v.text {
+				variant = candidate
+			}
+		}
+		return r.resolve(variant.value)
+	default:
+		return ftlValue{}, r.parser.errorf(selector.pos, "selector must be a variable or a term attribute")
+	}
+
+	cases := map[string]string{}
+	for _, variant := range node.variants {
+		v, err := r.resolve(variant.value)
+		if err != nil {
+			return ftlValue{}, err
+		}
+		if v.cases != nil {
+			return ftlValue{}, r.parser.errorf(variant.pos, "nested select expressions are not supported")
+		}
+		cases[variant.key] = v.text
+	}
+	if _, ok := cases[OtherCase]; !ok {
+		cases[OtherCase] = cases[node.variants[node.def].key]
+	}
+	return ftlValue{cases: cases, selector: strings.ReplaceAll(selector.variable, "-", "_")}, nil
+}
+
+func isFTLIdentifierStart(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
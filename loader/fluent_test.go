@@ -0,0 +1,112 @@
+package loader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseFluent(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    localizationFile
+		wantErr string
+	}{
+		{
+			name:  "messages and attributes",
+			value: "# comment\nhello = Hello, { $user-name }!\n\nlogin =\n    .placeholder = Email\n    .aria-label = Login input\n",
+			want: localizationFile{
+				"hello":             "Hello, {{.user_name}}!",
+				"login.placeholder": "Email",
+				"login.aria-label":  "Login input",
+			},
+		},
+		{
+			name:  "multiline",
+			value: "about =\n    First line\n\n      indented\n    last line  \nsingle = Text\n    continued\n",
+			want: localizationFile{
+				"about":  "First line\n\n  indented\nlast line",
+				"single": "Text\ncontinued",
+			},
+		},
+		{
+			name:  "terms and references",
+			value: "-brand = Firefox\n    .gender = feminine\nabout = About { -brand }\nwelcome = { about }, { NUMBER($count, minimumFractionDigits: 2) }\nliteral = { \"{\" } { \"\\u00E9\" } { 42 }\n",
+			want: localizationFile{
+				"about":   "About Firefox",
+				"welcome": "About Firefox, {{.count}}",
+				"literal": "{ é 42",
+			},
+		},
+		{
+			name: "selects",
+			value: "emails = You have { $count ->\n" +
+				"    [0] no emails\n" +
+				"    [one] one email\n" +
+				"   *[other] { $count } emails\n" +
+				"} today.\n" +
+				"-brand = Firefox\n    .gender = feminine\n" +
+				"updated = { -brand.gender ->\n    [masculine] He\n    [feminine] She\n   *[other] It\n} updated.\n" +
+				"role = { $role ->\n   *[admin] Admin\n    [user] User\n}\n",
+			want: localizationFile{
+				"emails.0":     "You have no emails today.",
+				"emails.one":   "You have one email today.",
+				"emails.other": "You have {{.count}} emails today.",
+				"updated":      "She updated.",
+				"role.admin":   "Admin",
+				"role.user":    "User",
+				"role.other":   "Admin",
+			},
+		},
+		{
+			name:    "no default variant",
+			value:   "hello = Hello\nemails = { $count ->\n    [one] one\n    [other] many\n}\n",
+			wantErr: "2:10: select expression has no default variant",
+		},
+		{
+			name:    "unknown message",
+			value:   "hello = Hello, { world }\n",
+			wantErr: "1:18: unknown message world",
+		},
+		{
+			name:    "unbalanced brace",
+			value:   "hello = é }\n",
+			wantErr: "1:11: unbalanced closing brace",
+		},
+		{
+			name:    "indented entry",
+			value:   "\n  hello = Hello\n",
+			wantErr: "2:3: expected a message, term or comment at the start of the line",
+		},
+		{
+			name:    "self reference",
+			value:   "hello = { hello }\n",
+			wantErr: "1:11: hello references itself",
+		},
+		{
+			name:    "two selects",
+			value:   "hello = { $a ->\n   *[x] x\n} { $b ->\n   *[y] y\n}\n",
+			wantErr: "3:3: only one select expression per pattern is supported",
+		},
+		{
+			name:    "no value",
+			value:   "hello =\n",
+			wantErr: "1:1: hello has no value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := localizationFile{}
+			err := parseFluent([]byte(tt.value), &got)
+			if err != nil || tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("parseFluent() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFluent() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package loader
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseINI parses an INI file of key = value pairs, ; and # comments and
+// [section] headers. Sections are key segments, e.g. hello in [errors] is
+// errors.hello, and values may be quoted to keep their surrounding white
+// space.
+func parseINI(value []byte, l *localizationFile) error {
+	localizations := localizationFile{}
+	section := ""
+	lines := strings.Split(strings.TrimPrefix(string(value), "\ufeff"), "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || line[0] == ';' || line[0] == '#':
+			continue
+		case line[0] == '[':
+			if line[len(line)-1] != ']' {
+				return fmt.Errorf("line %d: unterminated section %q", i+1, line)
+			}
+			section = strings.Trim(strings.TrimSpace(line[1:len(line)-1]), ".")
+			continue
+		}
+
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return fmt.Errorf("line %d: expected key = value, got %q", i+1, line)
+		}
+		key := strings.TrimSpace(line[:eq])
+		if key == "" {
+			return fmt.Errorf("line %d: empty key", i+1)
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+		localizations[key] = unquoteINI(strings.TrimSpace(line[eq+1:]))
+	}
+	*l = localizations
+	return nil
+}
+
+// unquoteINI removes the double or single quotes around value, if any.
+func unquoteINI(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,49 @@
+package loader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseINI(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    localizationFile
+		wantErr bool
+	}{
+		{
+			name:  "sections",
+			value: "; comment\n# comment\ntitle = Welcome\n\n[errors]\nnot_found = Not found\r\n[errors.auth]\ndenied = \" denied \"\nquote = 'it''s'\n",
+			want: localizationFile{
+				"title":              "Welcome",
+				"errors.not_found":   "Not found",
+				"errors.auth.denied": " denied ",
+				"errors.auth.quote":  "it''s",
+			},
+		},
+		{
+			name:    "unterminated section",
+			value:   "[errors\nnot_found = Not found\n",
+			wantErr: true,
+		},
+		{
+			name:    "no value",
+			value:   "[errors]\nnot_found\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := localizationFile{}
+			err := parseINI([]byte(tt.value), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseINI() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseINI() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+// Package loader loads the translation files go-localize understands, from
+// any file system, for the generator or at runtime.
+package loader
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	jsonFileExt = ".json"
+	yamlFileExt = ".yaml"
+	ymlFileExt  = ".yml"
+	tomlFileExt = ".toml"
+	csvFileExt  = ".csv"
+
+	propertiesFileExt = ".properties"
+	iniFileExt        = ".ini"
+	arbFileExt        = ".arb"
+	fluentFileExt     = ".ftl"
+
+	androidFileExt     = ".xml"
+	stringsFileExt     = ".strings"
+	stringsdictFileExt = ".stringsdict"
+)
+
+// OtherCase is the select case used when no case matches the selector, which
+// every nested object of select cases must include.
+const OtherCase = "other"
+
+var (
+	// ErrNoOtherCase is returned when a nested object of select cases has no
+	// other case.
+	ErrNoOtherCase = errors.New("select cases have no " + OtherCase + " case")
+	// ErrInvalidValue is returned for values that are neither a string nor an
+	// object of select cases.
+	ErrInvalidValue = errors.New("value is neither a string nor an object of select cases")
+	// ErrNoLocale is returned for translation files that don't have a locale
+	// when there is no base locale.
+	ErrNoLocale = errors.New("file has no locale, set the base locale")
+)
+
+type localizationFile map[string]string
+
+// parsers are the translation file parsers by file extension.
+var parsers = map[string]func([]byte, *localizationFile) error{
+	jsonFileExt: func(value []byte, l *localizationFile) error {
+		raw := map[string]interface{}{}
+		if err := json.Unmarshal(value, &raw); err != nil {
+			return err
+		}
+		return flatten(raw, l)
+	},
+	yamlFileExt: parseYAML,
+	ymlFileExt:  parseYAML,
+	tomlFileExt: func(value []byte, l *localizationFile) error {
+		raw := map[string]interface{}{}
+		if _, err := toml.Decode(string(value), &raw); err != nil {
+			return err
+		}
+		return flatten(raw, l)
+	},
+	csvFileExt: parseCSV,
+
+	propertiesFileExt: parseProperties,
+	iniFileExt:        parseINI,
+	arbFileExt:        parseARB,
+	fluentFileExt:     parseFluent,
+
+	androidFileExt:     parseAndroid,
+	stringsFileExt:     parseStrings,
+	stringsdictFileExt: parseStringsdict,
+}
+
+// metadataParsers are the parsers of the metadata of the translation files
+// that have any, by file extension: the @key metadata of ARB files and the
+// selectors of the plurals and select expressions of the others.
+var metadataParsers = map[string]func([]byte) (map[string]Metadata, error){
+	arbFileExt:    parseARBMetadata,
+	fluentFileExt: parseFluentMetadata,
+
+	androidFileExt:     parseAndroidMetadata,
+	stringsdictFileExt: parseStringsdictMetadata,
+}
+
+// localeDirs return the locale of the folders Android and Apple translation
+// files are in, by file extension, empty for the default folder, e.g. values,
+// and false for folders that aren't for a locale, e.g. values-night.
+var localeDirs = map[string]func(string) (string, bool){
+	androidFileExt:     androidLocale,
+	stringsFileExt:     appleLocale,
+	stringsdictFileExt: appleLocale,
+}
+
+// Options configures how translation files are loaded.
+type Options struct {
+	// BaseLocale is the locale of the translation files in the default
+	// Android values and Apple Base.lproj folders, which are skipped without
+	// it, and of the .properties and .arb files at the root without a
+	// locale, e.g. messages.properties, which fail with ErrNoLocale without
+	// it.
+	BaseLocale string
+}
+
+// Load loads the localizations of every translation file in the root folder
+// of fsys.
+func Load(fsys fs.FS, root string) (map[string]string, error) {
+	return Options{}.Load(fsys, root)
+}
+
+// Load loads the localizations of every translation file in the root folder
+// of fsys, like Load, keying the files that don't have a locale by the base
+// locale.
+func (o Options) Load(fsys fs.FS, root string) (map[string]string, error) {
+	files, err := Files(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	localizations := map[string]string{}
+	for _, file := range files {
+		newLocalizations, err := o.File(fsys, root, file)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range newLocalizations {
+			localizations[key] = value
+		}
+	}
+	return localizations, nil
+}
+
+// Files returns the translation files in the dir folder of fsys, in lexical
+// order.
+func Files(fsys fs.FS, dir string) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(fsys, dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(path)
+		if _, ok := parsers[ext]; ok && !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// File loads the localizations in file, keyed by its path relative to the root
+// folder, e.g. root/en/messages.json's hello is en.messages.hello. The locale
+// of Android and Apple files is their folder's, e.g. root/values-es/strings.xml
+// and root/es.lproj/Localizable.strings are es.strings and es.Localizable,
+// and the locale of .properties and ARB files with a locale suffix is the
+// suffix's, e.g. root/messages_es.properties is es.messages, or an ARB file's
+// @@locale. Files of an unsupported type, and Android and Apple files in
+// folders that aren't for a locale, e.g. values-night, have no localizations.
+func File(fsys fs.FS, root, file string) (map[string]string, error) {
+	return Options{}.File(fsys, root, file)
+}
+
+// File loads the localizations in file, like File, keying the files that
+// don't have a locale by the base locale.
+func (o Options) File(fsys fs.FS, root, file string) (map[string]string, error) {
+	newLocalizations := map[string]string{}
+
+	byteValue, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+
+	parse, ok := parsers[filepath.Ext(file)]
+	if !ok {
+		return nil, nil
+	}
+
+	localizationFile := localizationFile{}
+	if err = parse(byteValue, &localizationFile); err != nil {
+		return nil, fmt.Errorf("%v: %w", file, err)
+	}
+
+	slicePath, err := o.keyPath(root, file, localizationFile)
+	if err != nil || slicePath == nil {
+		return nil, err
+	}
+	delete(localizationFile, arbLocaleKey)
+	for key, value := range localizationFile {
+		newLocalizations[strings.Join(append(slicePath, key), ".")] = value
+	}
+
+	return newLocalizations, nil
+}
+
+// keyPath returns the key segments the localizations l of file are keyed by,
+// its path relative to the root folder with its locale worked out for the
+// file types that name it differently, or nil for files that are skipped.
+func (o Options) keyPath(root, file string, l localizationFile) ([]string, error) {
+	slicePath, err := getSlicePath(root, file)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := filepath.Ext(file)
+	if localeDir, ok := localeDirs[ext]; ok && len(slicePath) > 1 {
+		locale, ok := localeDir(slicePath[0])
+		if locale == "" {
+			locale = o.BaseLocale
+		}
+		if !ok || locale == "" {
+			return nil, nil
+		}
+		slicePath[0] = locale
+	}
+	if ext == propertiesFileExt || ext == arbFileExt {
+		name, locale := propertiesLocale(slicePath[len(slicePath)-1])
+		if arbLocale, ok := l[arbLocaleKey]; ok {
+			locale = strings.ReplaceAll(arbLocale, "_", "-")
+		}
+		if locale == "" && len(slicePath) == 1 {
+			// A file at the root without a locale suffix, e.g.
+			// messages.properties, is the base bundle.
+			if o.BaseLocale == "" {
+				return nil, fmt.Errorf("%w: %v", ErrNoLocale, file)
+			}
+			locale = o.BaseLocale
+		}
+		if locale != "" {
+			slicePath[len(slicePath)-1] = name
+			slicePath = append([]string{locale}, slicePath...)
+		}
+	}
+	return slicePath, nil
+}
+
+func parseYAML(value []byte, l *localizationFile) error {
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(value, &raw); err != nil {
+		return err
+	}
+	return flatten(raw, l)
+}
+
+// flatten sets the localizations of l from the decoded values of a file.
+// Nested objects are select cases, keyed by the value's key and the case,
+// e.g. {"invite": {"female": "...", "other": "..."}} is invite.female and
+// invite.other.
+func flatten(raw map[string]interface{}, l *localizationFile) error {
+	localizations := localizationFile{}
+	for key, value := range raw {
+		cases, ok := selectCases(value)
+		if !ok {
+			str, ok := scalar(value)
+			if !ok {
+				return fmt.Errorf("%w: %v", ErrInvalidValue, key)
+			}
+			localizations[key] = str
+			continue
+		}
+
+		if _, ok := cases[OtherCase]; !ok {
+			return fmt.Errorf("%w: %v", ErrNoOtherCase, key)
+		}
+		for c, value := range cases {
+			str, ok := scalar(value)
+			if !ok {
+				return fmt.Errorf("%w: %v.%v", ErrInvalidValue, key, c)
+			}
+			localizations[key+"."+c] = str
+		}
+	}
+	*l = localizations
+	return nil
+}
+
+// selectCases returns value as select cases by case, if it is an object.
+func selectCases(value interface{}) (map[string]interface{}, bool) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return value, true
+	case map[interface{}]interface{}:
+		cases := make(map[string]interface{}, len(value))
+		for c, v := range value {
+			cases[fmt.Sprint(c)] = v
+		}
+		return cases, true
+	}
+	return nil, false
+}
+
+// scalar returns value as a localization, if it is a string, number or bool.
+func scalar(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return value, true
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(value), true
+	}
+	return "", false
+}
+
+func parseCSV(value []byte, l *localizationFile) error {
+	r := csv.NewReader(bytes.NewReader(value))
+	localizations := localizationFile{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		localizations[record[0]] = record[1]
+	}
+	*l = localizations
+	return nil
+}
+
+// getSlicePath returns the key segments for file, from its path relative to
+// the input root, e.g. root/en/customer/messages.json is en, customer,
+// messages.
+func getSlicePath(root, file string) ([]string, error) {
+	rel, err := filepath.Rel(root, file)
+	if err != nil {
+		// One of root and file is absolute and the other relative.
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		absFile, err := filepath.Abs(file)
+		if err != nil {
+			return nil, err
+		}
+		if rel, err = filepath.Rel(absRoot, absFile); err != nil {
+			return nil, err
+		}
+	}
+
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return nil, fmt.Errorf("%v is not inside the input folder %v", file, root)
+	}
+
+	return strings.Split(strings.TrimSuffix(rel, filepath.Ext(rel)), "/"), nil
+}
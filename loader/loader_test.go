@@ -0,0 +1,493 @@
+package loader
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+//go:embed testdata
+var testdata embed.FS
+
+func TestLoad(t *testing.T) {
+	zipFS := func() fs.FS {
+		b := &bytes.Buffer{}
+		w := zip.NewWriter(b)
+		f, err := w.Create("locales/en/messages.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(`{"hello": "hello"}`)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		r, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	type args struct {
+		fsys fs.FS
+		root string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "os",
+			args: args{os.DirFS("../mock"), "dir"},
+			want: map[string]string{
+				"sub.valid_json.test": "test",
+				"valid_json.test":     "test",
+				"valid_yaml.test":     "test",
+				"valid_csv.test":      "test",
+				"valid_toml.test":     "test",
+			},
+		},
+		{
+			name: "map",
+			args: args{fstest.MapFS{
+				"en/messages.json":  {Data: []byte(`{"hello": "hello"}`)},
+				"es/messages.yml":   {Data: []byte(`hello: hola`)},
+				"es/dont_parse.txt": {Data: []byte(`hello`)},
+			}, "."},
+			want: map[string]string{
+				"en.messages.hello": "hello",
+				"es.messages.hello": "hola",
+			},
+		},
+		{
+			name: "zip",
+			args: args{zipFS(), "locales"},
+			want: map[string]string{
+				"en.messages.hello": "hello",
+			},
+		},
+		{
+			name: "embed",
+			args: args{testdata, "testdata"},
+			want: map[string]string{
+				"en.messages.hello": "hello",
+				"es.messages.hello": "hola",
+			},
+		},
+		{
+			name: "mobile",
+			args: args{fstest.MapFS{
+				"res/values-es/strings.xml":           {Data: []byte(`<resources><string name="hello">hola %s</string></resources>`)},
+				"res/es.lproj/Localizable.strings":    {Data: []byte(`"bye" = "adiós";`)},
+				"res/pt_BR.lproj/Localizable.strings": {Data: []byte(`"bye" = "tchau";`)},
+			}, "res"},
+			want: map[string]string{
+				"es.strings.hello":      "hola {{.arg1}}",
+				"es.Localizable.bye":    "adiós",
+				"pt-BR.Localizable.bye": "tchau",
+			},
+		},
+		{
+			name: "properties and ini",
+			args: args{fstest.MapFS{
+				"res/messages_es.properties":    {Data: []byte("hello=hola")},
+				"res/messages_pt_BR.properties": {Data: []byte("hello=ol\\u00e1")},
+				"res/en/errors.ini":             {Data: []byte("[auth]\ndenied = Denied")},
+			}, "res"},
+			want: map[string]string{
+				"es.messages.hello":     "hola",
+				"pt-BR.messages.hello":  "olá",
+				"en.errors.auth.denied": "Denied",
+			},
+		},
+		{
+			name: "properties base bundle without base locale",
+			args: args{fstest.MapFS{
+				"res/messages.properties":    {Data: []byte("hello=hello")},
+				"res/messages_es.properties": {Data: []byte("hello=hola")},
+			}, "res"},
+			wantErr: true,
+		},
+		{
+			name: "arb",
+			args: args{fstest.MapFS{
+				"l10n/app_en.arb":    {Data: []byte(`{"hello": "Hello {name}", "@hello": {"description": "Greeting"}}`)},
+				"l10n/app_pt_BR.arb": {Data: []byte(`{"hello": "Olá {name}"}`)},
+				"l10n/strings.arb":   {Data: []byte(`{"@@locale": "es", "hello": "Hola {name}"}`)},
+			}, "l10n"},
+			want: map[string]string{
+				"en.app.hello":     "Hello {{.name}}",
+				"pt-BR.app.hello":  "Olá {{.name}}",
+				"es.strings.hello": "Hola {{.name}}",
+			},
+		},
+		{
+			name: "fluent",
+			args: args{fstest.MapFS{
+				"locales/en/main.ftl": {Data: []byte("hello = Hello, { $name }!\nlogin =\n    .placeholder = Email\n")},
+			}, "locales"},
+			want: map[string]string{
+				"en.main.hello":             "Hello, {{.name}}!",
+				"en.main.login.placeholder": "Email",
+			},
+		},
+		{
+			name: "fluent error",
+			args: args{fstest.MapFS{
+				"locales/en/main.ftl": {Data: []byte("hello = {")},
+			}, "locales"},
+			wantErr: true,
+		},
+		{
+			name: "mobile default and qualified folders",
+			args: args{fstest.MapFS{
+				"res/values/strings.xml":       {Data: []byte(`<resources><string name="hello">hello</string></resources>`)},
+				"res/values-es/strings.xml":    {Data: []byte(`<resources><string name="hello">hola</string></resources>`)},
+				"res/values-night/strings.xml": {Data: []byte(`<resources><string name="hello">good night</string></resources>`)},
+				"res/values-v21/styles.xml":    {Data: []byte(`<resources><style name="AppTheme"/></resources>`)},
+				"res/Base.lproj/Main.strings":  {Data: []byte(`"title" = "Main";`)},
+			}, "res"},
+			want: map[string]string{
+				"es.strings.hello": "hola",
+			},
+		},
+		{
+			name:    "invalid file",
+			args:    args{os.DirFS(".."), "mock"},
+			wantErr: true,
+		},
+		{
+			name:    "missing root",
+			args:    args{fstest.MapFS{}, "locales"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.args.fsys, tt.args.root)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptions_Load(t *testing.T) {
+	fsys := fstest.MapFS{
+		"res/values/strings.xml":       {Data: []byte(`<resources><string name="hello">hello</string></resources>`)},
+		"res/values-es/strings.xml":    {Data: []byte(`<resources><string name="hello">hola</string></resources>`)},
+		"res/values-night/strings.xml": {Data: []byte(`<resources><string name="hello">good night</string></resources>`)},
+		"res/Base.lproj/Main.strings":  {Data: []byte(`"title" = "Main";`)},
+		"res/messages.properties":      {Data: []byte("bye=bye")},
+		"res/messages_es.properties":   {Data: []byte("bye=adiós")},
+	}
+	got, err := Options{BaseLocale: "en"}.Load(fsys, "res")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := map[string]string{
+		"en.strings.hello": "hello",
+		"es.strings.hello": "hola",
+		"en.Main.title":    "Main",
+		"en.messages.bye":  "bye",
+		"es.messages.bye":  "adiós",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() got = %v, want %v", got, want)
+	}
+}
+
+func TestFile(t *testing.T) {
+	type args struct {
+		root string
+		file string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "valid json",
+			args: args{"mock", "mock/valid.json"},
+			want: map[string]string{"valid.test1": "test2"},
+		},
+		{
+			name: "valid yaml",
+			args: args{"mock", "mock/valid.yaml"},
+			want: map[string]string{"valid.test1": "test2"},
+		},
+		{
+			name:    "file not exist",
+			args:    args{"mock", "mock/non_exist.json"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			args:    args{"mock", "mock/invalid.json"},
+			wantErr: true,
+		},
+		{
+			name:    "outside root",
+			args:    args{"mock/dir", "mock/valid.json"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := File(os.DirFS(".."), tt.args.root, tt.args.file)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("File() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getSlicePath(t *testing.T) {
+	type args struct {
+		root string
+		file string
+	}
+	abs, err := filepath.Abs("../mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "relative",
+			args: args{"../mock", "../mock/valid.json"},
+			want: []string{"valid"},
+		},
+		{
+			name: "nested",
+			args: args{"../mock/layers/base", "../mock/layers/base/en/messages.json"},
+			want: []string{"en", "messages"},
+		},
+		{
+			name: "trailing slash",
+			args: args{"../mock/layers/base/", "../mock/layers/base/en/messages.json"},
+			want: []string{"en", "messages"},
+		},
+		{
+			name: "unclean",
+			args: args{"../mock/./layers/base", "../mock/layers/base/en/messages.json"},
+			want: []string{"en", "messages"},
+		},
+		{
+			name: "absolute",
+			args: args{abs, filepath.Join(abs, "layers/base/en/messages.json")},
+			want: []string{"layers", "base", "en", "messages"},
+		},
+		{
+			name: "absolute root relative file",
+			args: args{abs + "/", "../mock/layers/base/en/messages.json"},
+			want: []string{"layers", "base", "en", "messages"},
+		},
+		{
+			name: "relative root absolute file",
+			args: args{"../mock", filepath.Join(abs, "valid.json")},
+			want: []string{"valid"},
+		},
+		{
+			name: "dots in file name",
+			args: args{"../mock", "../mock/en/messages.v2.json"},
+			want: []string{"en", "messages.v2"},
+		},
+		{
+			name:    "outside root",
+			args:    args{"../mock/dir", "../mock/valid.json"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getSlicePath(tt.args.root, tt.args.file)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getSlicePath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getSlicePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	type args struct {
+		dir string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			args: args{"mock/dir"},
+			want: []string{
+				"mock/dir/sub/valid_json.json",
+				"mock/dir/valid_csv.csv",
+				"mock/dir/valid_json.json",
+				"mock/dir/valid_toml.toml",
+				"mock/dir/valid_yaml.yaml",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Files(os.DirFS(".."), tt.args.dir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Files() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Files() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseCSV(t *testing.T) {
+	type args struct {
+		value []byte
+		l     *localizationFile
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		want    *localizationFile
+	}{
+		{
+			name: "valid",
+			args: args{
+				value: []byte("test,test"),
+				l:     &localizationFile{},
+			},
+			want: &localizationFile{
+				"test": "test",
+			},
+		},
+		{
+			name: "not valid",
+			args: args{
+				value: []byte("test,test\ntest,test,test"),
+				l:     &localizationFile{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "record length above 2",
+			args: args{
+				value: []byte("test,test,test"),
+				l:     &localizationFile{},
+			},
+			want: &localizationFile{"test": "test"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseCSV(tt.args.value, tt.args.l)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseCSV() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(tt.args.l, tt.want) && !tt.wantErr {
+				t.Errorf("parseCSV() got = %v, want %v", tt.args.l, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parsers(t *testing.T) {
+	selects := &localizationFile{
+		"hello":          "hello",
+		"updated.female": "updated her profile",
+		"updated.other":  "updated their profile",
+		"count":          "1",
+	}
+	tests := []struct {
+		name    string
+		ext     string
+		value   string
+		want    *localizationFile
+		wantErr error
+	}{
+		{
+			name:  "json select cases",
+			ext:   jsonFileExt,
+			value: `{"hello": "hello", "count": 1, "updated": {"female": "updated her profile", "other": "updated their profile"}}`,
+			want:  selects,
+		},
+		{
+			name:  "yaml select cases",
+			ext:   yamlFileExt,
+			value: "hello: hello\ncount: 1\nupdated:\n  female: updated her profile\n  other: updated their profile\n",
+			want:  selects,
+		},
+		{
+			name:  "toml select cases",
+			ext:   tomlFileExt,
+			value: "hello = \"hello\"\ncount = 1\n[updated]\nfemale = \"updated her profile\"\nother = \"updated their profile\"\n",
+			want:  selects,
+		},
+		{
+			name:    "no other case",
+			ext:     jsonFileExt,
+			value:   `{"updated": {"female": "updated her profile"}}`,
+			wantErr: ErrNoOtherCase,
+		},
+		{
+			name:    "nested select cases",
+			ext:     yamlFileExt,
+			value:   "updated:\n  female:\n    other: updated her profile\n  other: updated their profile\n",
+			wantErr: ErrInvalidValue,
+		},
+		{
+			name:    "list",
+			ext:     jsonFileExt,
+			value:   `{"hello": ["hello"]}`,
+			wantErr: ErrInvalidValue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &localizationFile{}
+			err := parsers[tt.ext]([]byte(tt.value), got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("parsers[%v]() error = %v, wantErr %v", tt.ext, err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsers[%v]() got = %v, want %v", tt.ext, got, tt.want)
+			}
+		})
+	}
+}
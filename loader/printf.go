@@ -0,0 +1,104 @@
+package loader
+
+import (
+	"strconv"
+	"strings"
+)
+
+// printfPlaceholders converts the printf style placeholders of mobile
+// translation files, e.g. %1$s, %d or %@, to substitutions of the replacement
+// named after the argument, e.g. {{.arg1}}. %% is converted to %.
+func printfPlaceholders(str string) string {
+	if !strings.Contains(str, "%") {
+		return str
+	}
+
+	b := &strings.Builder{}
+	arg := 0
+	for i := 0; i < len(str); i++ {
+		if str[i] != '%' {
+			b.WriteByte(str[i])
+			continue
+		}
+		if i+1 < len(str) && str[i+1] == '%' {
+			b.WriteByte('%')
+			i++
+			continue
+		}
+
+		end, index, ok := printfVerb(str[i+1:])
+		if !ok {
+			b.WriteByte('%')
+			continue
+		}
+		if index == 0 {
+			arg++
+			index = arg
+		}
+		b.WriteString("{{.arg" + strconv.Itoa(index) + "}}")
+		i += end
+	}
+	return b.String()
+}
+
+// printfCount returns the replacement of the first integer printf style
+// placeholder of str, e.g. arg2 for %2$d, which is the count of the plurals of
+// mobile translation files, or "" if it has none.
+func printfCount(str string) string {
+	arg := 0
+	for i := 0; i < len(str); i++ {
+		if str[i] != '%' {
+			continue
+		}
+		if i+1 < len(str) && str[i+1] == '%' {
+			i++
+			continue
+		}
+
+		end, index, ok := printfVerb(str[i+1:])
+		if !ok {
+			continue
+		}
+		if index == 0 {
+			arg++
+			index = arg
+		}
+		if strings.IndexByte("diu", str[i+end]) >= 0 {
+			return "arg" + strconv.Itoa(index)
+		}
+		i += end
+	}
+	return ""
+}
+
+// printfVerb parses the printf placeholder at the start of str, just after
+// its %, returning its length, its explicit argument index if it has one, e.g.
+// 1 for 1$s, and whether it is a placeholder.
+func printfVerb(str string) (int, int, bool) {
+	i := 0
+	digits := 0
+	for i < len(str) && str[i] >= '0' && str[i] <= '9' {
+		i++
+		digits++
+	}
+	index := 0
+	if digits > 0 && i < len(str) && str[i] == '$' {
+		index, _ = strconv.Atoi(str[:i])
+		i++
+	} else {
+		i = 0
+	}
+
+	// The space flag is left out, so that a literal "% " is not mistaken for
+	// a placeholder, e.g. in "100% sure".
+	for i < len(str) && strings.IndexByte("-+#0123456789.", str[i]) >= 0 {
+		i++
+	}
+	for i < len(str) && strings.IndexByte("hlqLjzt", str[i]) >= 0 {
+		i++
+	}
+	if i >= len(str) || strings.IndexByte("@sdiuxXofeEgGcSpaA", str[i]) < 0 {
+		return 0, 0, false
+	}
+	return i + 1, index, true
+}
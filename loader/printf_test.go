@@ -0,0 +1,45 @@
+package loader
+
+import "testing"
+
+func Test_printfPlaceholders(t *testing.T) {
+	tests := []struct {
+		name string
+		str  string
+		want string
+	}{
+		{name: "none", str: "hello", want: "hello"},
+		{name: "indexed", str: "%2$s has %1$d items", want: "{{.arg2}} has {{.arg1}} items"},
+		{name: "sequential", str: "%@ has %d items", want: "{{.arg1}} has {{.arg2}} items"},
+		{name: "flags and length", str: "%05.2f and %lld", want: "{{.arg1}} and {{.arg2}}"},
+		{name: "percent", str: "100%% of %s", want: "100% of {{.arg1}}"},
+		{name: "not a verb", str: "100% sure", want: "100% sure"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := printfPlaceholders(tt.str); got != tt.want {
+				t.Errorf("printfPlaceholders() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_printfCount(t *testing.T) {
+	tests := []struct {
+		name string
+		str  string
+		want string
+	}{
+		{name: "none", str: "%s items", want: ""},
+		{name: "sequential", str: "%@ has %d items", want: "arg2"},
+		{name: "indexed", str: "%2$s has %1$lld items", want: "arg1"},
+		{name: "percent", str: "100%% of %i", want: "arg1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := printfCount(tt.str); got != tt.want {
+				t.Errorf("printfCount() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package loader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// parseProperties parses a Java .properties file, as read by
+// java.util.Properties: key=value, key:value or key value pairs, # and !
+// comments, backslash line continuations and \uXXXX escapes. Files that are
+// not valid UTF-8 are read as ISO-8859-1, the encoding older Java versions
+// require.
+func parseProperties(value []byte, l *localizationFile) error {
+	str := string(value)
+	if !utf8.ValidString(str) {
+		runes := make([]rune, len(value))
+		for i, b := range value {
+			runes[i] = rune(b)
+		}
+		str = string(runes)
+	}
+	str = strings.TrimPrefix(str, "\ufeff")
+
+	localizations := localizationFile{}
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(str, "\r\n", "\n"), "\r", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		start := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		for continued(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		if continued(line) {
+			line = line[:len(line)-1]
+		}
+
+		key, rest := propertiesKey(line)
+		k, err := propertiesUnescape(key)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", start, err)
+		}
+		v, err := propertiesUnescape(rest)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", start, err)
+		}
+		localizations[k] = v
+	}
+	*l = localizations
+	return nil
+}
+
+// continued returns whether line continues on the next line, ending in an
+// odd number of backslashes.
+func continued(line string) bool {
+	n := 0
+	for n < len(line) && line[len(line)-1-n] == '\\' {
+		n++
+	}
+	return n%2 == 1
+}
+
+// propertiesKey splits a logical line into its escaped key and value. The key
+// ends at the first unescaped =, : or white space, which may be surrounded by
+// white space.
+func propertiesKey(line string) (string, string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return line[:end], rest
+}
+
+// propertiesUnescape replaces the escapes of a key or value.
+func propertiesUnescape(str string) (string, error) {
+	if !strings.Contains(str, `\`) {
+		return str, nil
+	}
+
+	b := &strings.Builder{}
+	for i := 0; i < len(str); i++ {
+		if str[i] != '\\' || i+1 == len(str) {
+			b.WriteByte(str[i])
+			continue
+		}
+		i++
+		switch str[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			r, n, ok := unicodeEscape(str[i+1:])
+			if !ok {
+				end := i + 5
+				if end > len(str) {
+					end = len(str)
+				}
+				return "", fmt.Errorf("malformed \\uXXXX escape %q", str[i-1:end])
+			}
+			b.WriteRune(r)
+			i += n
+		default:
+			b.WriteByte(str[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// unicodeEscape decodes the hex digits of a \uXXXX escape at the start of
+// str, returning the rune and the number of bytes used. A UTF-16 surrogate
+// pair written as two escapes, e.g. \uD83D\uDE00, is decoded as one rune.
+func unicodeEscape(str string) (rune, int, bool) {
+	if len(str) < 4 {
+		return 0, 0, false
+	}
+	r, err := strconv.ParseUint(str[:4], 16, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	if utf16.IsSurrogate(rune(r)) && len(str) >= 10 && str[4] == '\\' && (str[5] == 'u' || str[5] == 'U') {
+		if low, err := strconv.ParseUint(str[6:10], 16, 32); err == nil {
+			if pair := utf16.DecodeRune(rune(r), rune(low)); pair != unicode.ReplacementChar {
+				return pair, 10, true
+			}
+		}
+	}
+	return rune(r), 4, true
+}
+
+// propertiesLocale splits the locale suffix off the name of a .properties
+// file, e.g. messages and es for messages_es or messages and pt-BR for
+// messages_pt_BR. The locale is a two letter language, optionally followed by
+// a script, e.g. Latn, and a region, e.g. BR or 419. Names without a locale
+// suffix are returned as they are.
+func propertiesLocale(name string) (string, string) {
+	parts := strings.Split(name, "_")
+	i := len(parts) - 1
+	var subtags []string
+	for ; i > 1; i-- {
+		if !isRegion(parts[i]) && !isScript(parts[i]) {
+			break
+		}
+		subtags = append([]string{parts[i]}, subtags...)
+	}
+	if i < 1 || !isLanguage(parts[i]) || len(subtags) > 2 {
+		return name, ""
+	}
+	return strings.Join(parts[:i], "_"), strings.Join(append([]string{parts[i]}, subtags...), "-")
+}
+
+func isLanguage(str string) bool {
+	return len(str) == 2 && isLetters(str, 'a', 'z')
+}
+
+func isScript(str string) bool {
+	return len(str) == 4 && isLetters(str[:1], 'A', 'Z') && isLetters(str[1:], 'a', 'z')
+}
+
+func isRegion(str string) bool {
+	return (len(str) == 2 && isLetters(str, 'A', 'Z')) || (len(str) == 3 && isLetters(str, '0', '9'))
+}
+
+func isLetters(str string, from, to byte) bool {
+	for i := 0; i < len(str); i++ {
+		if str[i] < from || str[i] > to {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package loader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseProperties(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    localizationFile
+		wantErr bool
+	}{
+		{
+			name:  "separators and comments",
+			value: "# comment\n! comment\n\nhello=hello\nbye : bye\r\nwelcome   welcome back\nempty=\n",
+			want: localizationFile{
+				"hello":   "hello",
+				"bye":     "bye",
+				"welcome": "welcome back",
+				"empty":   "",
+			},
+		},
+		{
+			name:  "escapes",
+			value: "caf\\u00e9=caf\\u00e9\nkey\\ with\\=escapes=tab\\there\\nnewline\nbackslash=C:\\\\\n",
+			want: localizationFile{
+				"café":             "café",
+				"key with=escapes": "tab\there\nnewline",
+				"backslash":        "C:\\",
+			},
+		},
+		{
+			name:  "surrogate pairs",
+			value: "smile=\\uD83D\\uDE00 \\uD83D!\n",
+			want:  localizationFile{"smile": "\U0001F600 \uFFFD!"},
+		},
+		{
+			name:  "continuations",
+			value: "long=first, \\\n    second, \\\n    third\n# not continued \\\nnext=next\n",
+			want: localizationFile{
+				"long": "first, second, third",
+				"next": "next",
+			},
+		},
+		{
+			name:  "latin-1",
+			value: "hello=caf\xe9\n",
+			want:  localizationFile{"hello": "café"},
+		},
+		{
+			name:    "malformed unicode escape",
+			value:   "hello=\\u00zz\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := localizationFile{}
+			err := parseProperties([]byte(tt.value), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseProperties() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseProperties() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_propertiesLocale(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantName   string
+		wantLocale string
+	}{
+		{name: "messages_es", wantName: "messages", wantLocale: "es"},
+		{name: "messages_pt_BR", wantName: "messages", wantLocale: "pt-BR"},
+		{name: "messages_sr_Latn_RS", wantName: "messages", wantLocale: "sr-Latn-RS"},
+		{name: "error_messages_es_419", wantName: "error_messages", wantLocale: "es-419"},
+		{name: "messages", wantName: "messages"},
+		{name: "error_msg", wantName: "error_msg"},
+		{name: "es", wantName: "es"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, locale := propertiesLocale(tt.name)
+			if name != tt.wantName || locale != tt.wantLocale {
+				t.Errorf("propertiesLocale() = %v, %v, want %v, %v", name, locale, tt.wantName, tt.wantLocale)
+			}
+		})
+	}
+}
@@ -1,228 +1,224 @@
 package main
 
 import (
-	"bytes"
-	"encoding/csv"
-	"encoding/json"
 	"errors"
 	"flag"
-	"fmt"
-	"io"
-	"io/ioutil"
 	"log"
 	"os"
-	"path/filepath"
 	"strings"
-	"time"
 
-	"github.com/BurntSushi/toml"
-	"gopkg.in/yaml.v2"
+	"github.com/m1/go-localize/generate"
 )
 
-const (
-	jsonFileExt = ".json"
-	yamlFileExt = ".yaml"
-	ymlFileExt  = ".yml"
-	tomlFileExt = ".toml"
-	csvFileExt  = ".csv"
-)
+// inputsFlag is a flag.Value collecting every occurrence of a repeated flag,
+// in the order they were given.
+type inputsFlag []string
+
+func (i *inputsFlag) String() string {
+	return strings.Join(*i, ",")
+}
+
+func (i *inputsFlag) Set(value string) error {
+	*i = append(*i, value)
+	return nil
+}
 
-type localizationFile map[string]string
+// flags are the CLI flags, left empty when not set. set has the names of the
+// flags given, so that flags given their zero value, e.g. -fake=false, still
+// override the config.
+type flags struct {
+	inputs             inputsFlag
+	output             string
+	pkg                string
+	filename           string
+	mode               string
+	buildTags          bool
+	fake               bool
+	baseLocale         string
+	validate           string
+	pseudo             string
+	pseudoLocale       string
+	pseudoPadding      int
+	bundles            string
+	bundleFormat       string
+	bundlePlaceholders string
+	typings            bool
+	android            string
+	apple              string
+	arb                string
+	set                map[string]bool
+}
 
 const (
 	defaultOutputDir = "localizations"
 )
 
 var (
-	input  = flag.String("input", "", "input localizations folder")
-	output = flag.String("output", "", "where to output the generated package")
+	cliFlags   flags
+	configFile = flag.String("config", "", "config file to use, defaults to go-localize.yaml or go-localize.toml in the working directory")
 
 	errFlagInputNotSet = errors.New("the flag -input must be set")
 )
 
+func init() {
+	flag.Var(&cliFlags.inputs, "input", "input localizations folder, repeat to layer overrides on top of earlier folders")
+	flag.StringVar(&cliFlags.output, "output", "", "where to output the generated package")
+	flag.StringVar(&cliFlags.pkg, "package", "", "package name of the generated file, defaults to the package already in the output folder or the output folder name")
+	flag.StringVar(&cliFlags.filename, "filename", "", "file name of the generated file, defaults to the package name")
+	flag.StringVar(&cliFlags.mode, "mode", "", "how to output the localizations, map for a map literal, embed for embedded per-locale data files or locales for a generated file per locale (default map)")
+	flag.BoolVar(&cliFlags.buildTags, "build-tags", false, "guard each locale's file with the i18n_<locale> and i18n_all build tags in locales mode")
+	flag.BoolVar(&cliFlags.fake, "fake", false, "generate a <package>test package with a Translator test double in the output folder")
+	flag.StringVar(&cliFlags.baseLocale, "base-locale", "", "locale the others are translated from, e.g. en, checked against by the missing_keys validation and the locale of files without one, e.g. in an Android values folder or messages.properties")
+	flag.StringVar(&cliFlags.validate, "validate", "", "comma separated validations failing generation, verbs to check every locale of a key uses the same fmt verbs or missing_keys to check every locale has the keys of -base-locale")
+	flag.StringVar(&cliFlags.pseudo, "pseudo", "", "base locale to pseudo-localize, e.g. en, adding the -pseudo-locale locale")
+	flag.StringVar(&cliFlags.pseudoLocale, "pseudo-locale", "", "pseudo-localized locale (default en-XA)")
+	flag.IntVar(&cliFlags.pseudoPadding, "pseudo-padding", 0, "percentage to pad pseudo-localizations by, negative for no padding (default 30)")
+	flag.StringVar(&cliFlags.bundles, "bundles", "", "folder to also write per-locale JSON bundles to, e.g. for a web frontend")
+	flag.StringVar(&cliFlags.bundleFormat, "bundle-format", "", "format of the JSON bundles, flat for keys like messages.hello or nested for objects per key segment (default flat)")
+	flag.StringVar(&cliFlags.bundlePlaceholders, "bundle-placeholders", "", "syntax to convert {{.name}} substitutions to in the JSON bundles, go to leave them, single for {name} or double for {{name}} (default go)")
+	flag.BoolVar(&cliFlags.typings, "typings", false, "also write TypeScript typings of every key and its placeholders to the -bundles folder")
+	flag.StringVar(&cliFlags.android, "android", "", "folder to also write Android string resources to, e.g. values-es/strings.xml")
+	flag.StringVar(&cliFlags.apple, "apple", "", "folder to also write Apple strings files to, e.g. es.lproj/Localizable.strings")
+	flag.StringVar(&cliFlags.arb, "arb", "", "folder to also write Flutter ARB files to, e.g. app_es.arb")
+}
+
 func main() {
 	flag.Parse()
+	cliFlags.set = map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		cliFlags.set[f.Name] = true
+	})
 
-	if err := run(input, output); err != nil {
-		log.Fatal(err.Error())
-	}
-}
-
-func run(in, out *string) error {
-	inputDir, outputDir, err := parseFlags(in, out)
+	cfg, err := loadConfig(*configFile)
 	if err != nil {
-		return err
+		log.Fatal(err.Error())
 	}
 
-	files, err := getLocalizationFiles(inputDir)
-	if err != nil {
-		return err
+	if err := run(cfg, cliFlags); err != nil {
+		log.Fatal(err.Error())
 	}
+}
 
-	localizations, err := generateLocalizations(files)
+func run(cfg config, f flags) error {
+	opts, err := parseFlags(cfg, f)
 	if err != nil {
 		return err
 	}
 
-	return generateFile(outputDir, localizations)
+	opts.Logger = log.New(os.Stderr, "", log.LstdFlags)
+	return generate.Run(opts)
 }
 
-func generateLocalizations(files []string) (map[string]string, error) {
-	localizations := map[string]string{}
-	for _, file := range files {
-		newLocalizations, err := getLocalizationsFromFile(file)
-		if err != nil {
-			return nil, err
-		}
-		for key, value := range newLocalizations {
-			localizations[key] = value
-		}
-	}
-	return localizations, nil
-}
+// parseFlags merges the flags over the config, flags that are set replacing
+// the config value entirely.
+func parseFlags(cfg config, f flags) (generate.Options, error) {
+	var inputDirs []string
+	var outputDir string
 
-func getLocalizationFiles(dir string) ([]string, error) {
-	var files []string
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		ext := filepath.Ext(path)
-		if !info.IsDir() && (ext == jsonFileExt || ext == yamlFileExt) {
-			files = append(files, path)
+	input := []string(f.inputs)
+	if len(input) == 0 {
+		input = cfg.Inputs
+	}
+	for _, dir := range input {
+		if dir != "" {
+			inputDirs = append(inputDirs, dir)
 		}
-		return nil
-	})
-	return files, err
-}
-
-func generateFile(output string, localizations map[string]string) error {
-	dir := output
-	parent := output
-	if strings.Contains(output, string(filepath.Separator)) {
-		parent = filepath.Base(dir)
 	}
-
-	err := os.MkdirAll(output, 0700)
-	if err != nil {
-		return err
+	if len(inputDirs) == 0 {
+		return generate.Options{}, errFlagInputNotSet
 	}
-
-	f, err := os.Create(fmt.Sprintf("%v/%v.go", dir, parent))
-	if err != nil {
-		return err
+	switch {
+	case f.output != "":
+		outputDir = f.output
+	case cfg.Output != "":
+		outputDir = cfg.Output
+	default:
+		outputDir = defaultOutputDir
 	}
 
-	maxWidth := 0
-	for name := range localizations {
-		if len(name) > maxWidth {
-			maxWidth = len(name)
-		}
+	opts := generate.Options{
+		Inputs:             inputDirs,
+		Output:             outputDir,
+		Package:            cfg.Package,
+		Filename:           cfg.Filename,
+		Mode:               generate.Mode(cfg.Mode),
+		BuildTags:          cfg.BuildTags,
+		Fake:               cfg.Fake,
+		BaseLocale:         cfg.BaseLocale,
+		Validations:        validations(cfg.Validations),
+		Pseudo:             cfg.Pseudo,
+		PseudoLocale:       cfg.PseudoLocale,
+		PseudoPadding:      cfg.PseudoPadding,
+		Bundles:            cfg.Bundles,
+		BundleFormat:       generate.BundleFormat(cfg.BundleFormat),
+		BundlePlaceholders: generate.Placeholders(cfg.BundlePlaceholders),
+		Typings:            cfg.Typings,
+		Android:            cfg.Android,
+		Apple:              cfg.Apple,
+		ARB:                cfg.ARB,
 	}
-
-	lineUp := func(name string) string {
-		return strings.Repeat(" ", maxWidth-len(name))
+	if f.pkg != "" || f.set["package"] {
+		opts.Package = f.pkg
 	}
-
-	return packageTemplate.Execute(f, struct {
-		Timestamp     time.Time
-		Localizations map[string]string
-		Package       string
-		LineUp        func(string) string
-	}{
-		Timestamp:     time.Now(),
-		Localizations: localizations,
-		Package:       parent,
-		LineUp:        lineUp,
-	})
-}
-
-func getLocalizationsFromFile(file string) (map[string]string, error) {
-	newLocalizations := map[string]string{}
-
-	openFile, err := os.Open(file)
-	if err != nil {
-		return nil, err
+	if f.filename != "" || f.set["filename"] {
+		opts.Filename = f.filename
 	}
-
-	byteValue, err := ioutil.ReadAll(openFile)
-	if err != nil {
-		return nil, err
-	}
-
-	localizationFile := localizationFile{}
-	ext := filepath.Ext(file)
-	switch ext {
-	case jsonFileExt:
-		err = json.Unmarshal(byteValue, &localizationFile)
-	case yamlFileExt, ymlFileExt:
-		err = yaml.Unmarshal(byteValue, &localizationFile)
-	case tomlFileExt:
-		_, err = toml.Decode(string(byteValue), &localizationFile)
-	case csvFileExt:
-		err = parseCSV(byteValue, &localizationFile)
-	default:
-		return nil, nil
+	if f.mode != "" || f.set["mode"] {
+		opts.Mode = generate.Mode(f.mode)
 	}
-
-	if err != nil {
-		return nil, err
+	if f.buildTags || f.set["build-tags"] {
+		opts.BuildTags = f.buildTags
 	}
-
-	slicePath := getSlicePath(file)
-	for key, value := range localizationFile {
-		newLocalizations[strings.Join(append(slicePath, key), ".")] = value
+	if f.fake || f.set["fake"] {
+		opts.Fake = f.fake
 	}
-
-	return newLocalizations, nil
-}
-
-func parseCSV(value []byte, l *localizationFile) error {
-	r := csv.NewReader(bytes.NewReader(value))
-	localizations := localizationFile{}
-	for {
-		record, err := r.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-		localizations[record[0]] = record[1]
+	if f.baseLocale != "" || f.set["base-locale"] {
+		opts.BaseLocale = f.baseLocale
 	}
-	*l = localizations
-	return nil
-}
-
-func getSlicePath(file string) []string {
-	dir, file := filepath.Split(file)
-
-	paths := strings.Replace(dir, *input, "", -1)
-	pathSlice := strings.Split(paths, string(filepath.Separator))
-
-	var strs []string
-	for _, part := range pathSlice {
-		part := strings.TrimSpace(part)
-		part = strings.Trim(part, "/")
-		if part != "" {
-			strs = append(strs, part)
-		}
+	if f.validate != "" || f.set["validate"] {
+		opts.Validations = validations(strings.Split(f.validate, ","))
 	}
-
-	strs = append(strs, strings.Replace(file, filepath.Ext(file), "", -1))
-	return strs
-}
-
-func parseFlags(input *string, output *string) (string, string, error) {
-	var inputDir, outputDir string
-
-	if *input == "" {
-		return "", "", errFlagInputNotSet
+	if f.pseudo != "" || f.set["pseudo"] {
+		opts.Pseudo = f.pseudo
 	}
-	if *output == "" {
-		outputDir = defaultOutputDir
-	} else {
-		outputDir = *output
+	if f.pseudoLocale != "" || f.set["pseudo-locale"] {
+		opts.PseudoLocale = f.pseudoLocale
+	}
+	if f.pseudoPadding != 0 || f.set["pseudo-padding"] {
+		opts.PseudoPadding = f.pseudoPadding
+	}
+	if f.bundles != "" || f.set["bundles"] {
+		opts.Bundles = f.bundles
+	}
+	if f.bundleFormat != "" || f.set["bundle-format"] {
+		opts.BundleFormat = generate.BundleFormat(f.bundleFormat)
+	}
+	if f.bundlePlaceholders != "" || f.set["bundle-placeholders"] {
+		opts.BundlePlaceholders = generate.Placeholders(f.bundlePlaceholders)
+	}
+	if f.typings || f.set["typings"] {
+		opts.Typings = f.typings
+	}
+	if f.android != "" || f.set["android"] {
+		opts.Android = f.android
+	}
+	if f.apple != "" || f.set["apple"] {
+		opts.Apple = f.apple
+	}
+	if f.arb != "" || f.set["arb"] {
+		opts.ARB = f.arb
 	}
 
-	inputDir = *input
+	return opts, nil
+}
 
-	return inputDir, outputDir, nil
+// validations returns the validations named, without blank names.
+func validations(names []string) []generate.Validation {
+	var v []generate.Validation
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			v = append(v, generate.Validation(name))
+		}
+	}
+	return v
 }
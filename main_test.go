@@ -3,18 +3,22 @@ package main
 import (
 	"reflect"
 	"testing"
+
+	"github.com/m1/go-localize/generate"
 )
 
 func Test_run(t *testing.T) {
 	type args struct {
-		in  *string
-		out *string
+		cfg config
+		f   flags
 	}
 
 	dirBlank := ""
 	dirValid := "examples/localizations_src"
 	dirTestFiles := "test_files"
 	dirWithBad := "mock"
+	dirLayerBase := "mock/layers/base"
+	dirLayerBrand := "mock/layers/brand"
 	tests := []struct {
 		name    string
 		args    args
@@ -23,326 +27,323 @@ func Test_run(t *testing.T) {
 		{
 			name: "valid",
 			args: args{
-				in:  &dirValid,
-				out: &dirTestFiles,
+				f: flags{inputs: []string{dirValid}, output: dirTestFiles},
+			},
+		},
+		{
+			name: "valid layers",
+			args: args{
+				f: flags{inputs: []string{dirLayerBase, dirLayerBrand}, output: dirTestFiles},
+			},
+		},
+		{
+			name: "valid config",
+			args: args{
+				cfg: config{Inputs: []string{dirValid}, Output: dirTestFiles, Mode: "embed"},
 			},
 		},
 		{
 			name: "not valid",
 			args: args{
-				in:  &dirBlank,
-				out: &dirBlank,
+				f: flags{inputs: []string{dirBlank}, output: dirBlank},
 			},
 			wantErr: true,
 		},
 		{
 			name: "not valid",
 			args: args{
-				in:  &dirWithBad,
-				out: &dirTestFiles,
+				f: flags{inputs: []string{dirWithBad}, output: dirTestFiles},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid pseudo",
+			args: args{
+				f: flags{inputs: []string{dirValid}, output: dirTestFiles, pseudo: "en"},
+			},
+		},
+		{
+			name: "not valid pseudo",
+			args: args{
+				f: flags{inputs: []string{dirValid}, output: dirTestFiles, pseudo: "fr"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "not valid mode",
+			args: args{
+				f: flags{inputs: []string{dirValid}, output: dirTestFiles, mode: "other"},
 			},
 			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := run(tt.args.in, tt.args.out); (err != nil) != tt.wantErr {
+			if err := run(tt.args.cfg, tt.args.f); (err != nil) != tt.wantErr {
 				t.Errorf("run() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func Test_generateLocalizations(t *testing.T) {
+func Test_parseFlags(t *testing.T) {
 	type args struct {
-		files []string
+		cfg config
+		f   flags
 	}
+
+	dirBlank := ""
+	dirOk := "input"
+	dirLayer := "layer"
+	pkg := "i18n"
+	filename := "i18n_gen.go"
+
 	tests := []struct {
 		name    string
 		args    args
-		want    map[string]string
-		wantErr bool
+		want    generate.Options
+		wantErr error
 	}{
 		{
 			name: "valid",
 			args: args{
-				files: []string{
-					"mock/dir/sub/valid_json.json",
-					"mock/dir/valid_json.json",
-					"mock/dir/valid_yaml.yaml",
-					"mock/dir/valid_csv.csv",
-					"mock/dir/valid_toml.toml",
-					"mock/dir/dont_parse.txt",
-				},
+				f: flags{inputs: []string{dirOk}, output: dirOk},
 			},
-			want: map[string]string{
-				"mock.dir.sub.valid_json.test": "test",
-				"mock.dir.valid_json.test":     "test",
-				"mock.dir.valid_yaml.test":     "test",
-				"mock.dir.valid_csv.test":      "test",
-				"mock.dir.valid_toml.test":     "test",
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk},
+		},
+		{
+			name: "layers",
+			args: args{
+				f: flags{inputs: []string{dirOk, dirLayer}, output: dirOk},
 			},
+			want: generate.Options{Inputs: []string{dirOk, dirLayer}, Output: dirOk},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := generateLocalizations(tt.args.files)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("generateLocalizations() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("generateLocalizations() got = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_generateFile(t *testing.T) {
-	type args struct {
-		output       string
-		translations map[string]string
-	}
-	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
-	}{
 		{
-			name: "valid",
+			name: "default output dir",
 			args: args{
-				output:       "test_files",
-				translations: map[string]string{"hello": "one"},
+				f: flags{inputs: []string{dirOk}},
 			},
+			want: generate.Options{Inputs: []string{dirOk}, Output: defaultOutputDir},
 		},
 		{
-			name: "invalid dir",
+			name: "config",
 			args: args{
-				output:       "",
-				translations: map[string]string{"hello": "one"},
+				cfg: config{Inputs: []string{dirOk, dirLayer}, Output: dirLayer},
 			},
-			wantErr: true,
+			want: generate.Options{Inputs: []string{dirOk, dirLayer}, Output: dirLayer},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if err := generateFile(tt.args.output, tt.args.translations); (err != nil) != tt.wantErr {
-				t.Errorf("generateFile() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
-	}
-}
-
-func Test_getLocalizationsFromFile(t *testing.T) {
-	type args struct {
-		file string
-	}
-	tests := []struct {
-		name    string
-		args    args
-		want    map[string]string
-		wantErr bool
-	}{
 		{
-			name: "valid json",
-			args: args{"mock/valid.json"},
-			want: map[string]string{"mock.valid.test1": "test2"},
+			name: "flags override config",
+			args: args{
+				cfg: config{Inputs: []string{dirOk, dirLayer}, Output: dirLayer},
+				f:   flags{inputs: []string{dirLayer}, output: dirOk},
+			},
+			want: generate.Options{Inputs: []string{dirLayer}, Output: dirOk},
 		},
 		{
-			name: "valid yaml",
-			args: args{"mock/valid.yaml"},
-			want: map[string]string{"mock.valid.test1": "test2"},
+			name: "package and filename",
+			args: args{
+				cfg: config{Package: "other", Filename: "other.go"},
+				f:   flags{inputs: []string{dirOk}, output: dirOk, pkg: pkg, filename: filename},
+			},
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk, Package: pkg, Filename: filename},
 		},
 		{
-			name:    "file not exist",
-			args:    args{"mock/non_exist.json"},
-			wantErr: true,
+			name: "package and filename from config",
+			args: args{
+				cfg: config{Package: pkg, Filename: filename},
+				f:   flags{inputs: []string{dirOk}, output: dirOk},
+			},
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk, Package: pkg, Filename: filename},
 		},
 		{
-			name:    "invalid json",
-			args:    args{"mock/invalid.json"},
-			wantErr: true,
+			name: "mode",
+			args: args{
+				cfg: config{Mode: "map"},
+				f:   flags{inputs: []string{dirOk}, output: dirOk, mode: "embed"},
+			},
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk, Mode: generate.ModeEmbed},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := getLocalizationsFromFile(tt.args.file)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("getLocalizationsFromFile() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("getLocalizationsFromFile() got = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_getSlicePath(t *testing.T) {
-	type args struct {
-		file string
-	}
-	tests := []struct {
-		name string
-		args args
-		want []string
-	}{
 		{
-			name: "valid",
-			args: args{"mock/valid.json"},
-			want: []string{"mock", "valid"},
+			name: "mode from config",
+			args: args{
+				cfg: config{Mode: "embed"},
+				f:   flags{inputs: []string{dirOk}, output: dirOk},
+			},
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk, Mode: generate.ModeEmbed},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := getSlicePath(tt.args.file); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("getSlicePath() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_parseFlags(t *testing.T) {
-	type args struct {
-		input  *string
-		output *string
-	}
-
-	dirBlank := ""
-	dirOk := "input"
-
-	tests := []struct {
-		name      string
-		args      args
-		inputDir  string
-		outputDir string
-		wantErr   error
-	}{
 		{
-			name: "valid",
+			name: "build tags",
 			args: args{
-				input:  &dirOk,
-				output: &dirOk,
+				f: flags{inputs: []string{dirOk}, output: dirOk, mode: "locales", buildTags: true},
 			},
-			inputDir:  dirOk,
-			outputDir: dirOk,
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk, Mode: generate.ModeLocales, BuildTags: true},
 		},
 		{
-			name: "default output dir",
+			name: "build tags from config",
 			args: args{
-				input:  &dirOk,
-				output: &dirBlank,
+				cfg: config{Mode: "locales", BuildTags: true},
+				f:   flags{inputs: []string{dirOk}, output: dirOk},
 			},
-			inputDir:  dirOk,
-			outputDir: defaultOutputDir,
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk, Mode: generate.ModeLocales, BuildTags: true},
 		},
 		{
-			name: "invalid input",
+			name: "fake",
 			args: args{
-				input:  &dirBlank,
-				output: &dirBlank,
+				f: flags{inputs: []string{dirOk}, output: dirOk, fake: true},
 			},
-			wantErr: errFlagInputNotSet,
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk, Fake: true},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			inputDir, outputDir, err := parseFlags(tt.args.input, tt.args.output)
-			if (err != nil) != (tt.wantErr != nil) || err != tt.wantErr {
-				t.Errorf("parseFlags() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if inputDir != tt.inputDir {
-				t.Errorf("parseFlags() got = %v, want %v", inputDir, tt.inputDir)
-			}
-			if outputDir != tt.outputDir {
-				t.Errorf("parseFlags() got1 = %v, want %v", outputDir, tt.outputDir)
-			}
-		})
-	}
-}
-
-func Test_getLocalizationFiles(t *testing.T) {
-	type args struct {
-		dir string
-	}
-	tests := []struct {
-		name    string
-		args    args
-		want    []string
-		wantErr bool
-	}{
 		{
-			name: "valid",
-			args: args{"mock/dir"},
-			want: []string{
-				"mock/dir/sub/valid_json.json",
-				"mock/dir/valid_json.json",
-				"mock/dir/valid_yaml.yaml",
+			name: "fake from config",
+			args: args{
+				cfg: config{Fake: true},
+				f:   flags{inputs: []string{dirOk}, output: dirOk},
 			},
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk, Fake: true},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := getLocalizationFiles(tt.args.dir)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("getLocalizationFiles() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("getLocalizationFiles() got = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_parseCSV(t *testing.T) {
-	type args struct {
-		value []byte
-		l     *localizationFile
-	}
-	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
-		want    *localizationFile
-	}{
 		{
-			name: "valid",
+			name: "validations",
 			args: args{
-				value: []byte("test,test"),
-				l:     &localizationFile{},
+				f: flags{inputs: []string{dirOk}, output: dirOk, baseLocale: "en", validate: "verbs, missing_keys"},
 			},
-			want: &localizationFile{
-				"test": "test",
+			want: generate.Options{
+				Inputs:      []string{dirOk},
+				Output:      dirOk,
+				BaseLocale:  "en",
+				Validations: []generate.Validation{generate.ValidationVerbs, generate.ValidationMissingKeys},
 			},
 		},
 		{
-			name: "not valid",
+			name: "validations from config",
 			args: args{
-				value: []byte("test,test\ntest,test,test"),
-				l:     &localizationFile{},
+				cfg: config{BaseLocale: "en", Validations: []string{"verbs"}},
+				f:   flags{inputs: []string{dirOk}, output: dirOk},
 			},
-			wantErr: true,
+			want: generate.Options{
+				Inputs:      []string{dirOk},
+				Output:      dirOk,
+				BaseLocale:  "en",
+				Validations: []generate.Validation{generate.ValidationVerbs},
+			},
+		},
+		{
+			name: "validations disabled over config",
+			args: args{
+				cfg: config{Validations: []string{"verbs"}},
+				f:   flags{inputs: []string{dirOk}, output: dirOk, set: map[string]bool{"validate": true}},
+			},
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk},
+		},
+		{
+			name: "bools disabled over config",
+			args: args{
+				cfg: config{BuildTags: true, Fake: true, Typings: true, PseudoPadding: 10},
+				f: flags{
+					inputs: []string{dirOk},
+					output: dirOk,
+					set:    map[string]bool{"build-tags": true, "fake": true, "typings": true, "pseudo-padding": true},
+				},
+			},
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk},
 		},
 		{
-			name: "record length above 2",
+			name: "pseudo",
 			args: args{
-				value: []byte("test,test,test"),
-				l:     &localizationFile{},
+				cfg: config{Pseudo: "en", PseudoLocale: "qps", PseudoPadding: 50},
+				f:   flags{inputs: []string{dirOk}, output: dirOk, pseudo: "en-GB", pseudoPadding: -1},
 			},
-			want: &localizationFile{"test": "test"},
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk, Pseudo: "en-GB", PseudoLocale: "qps", PseudoPadding: -1},
+		},
+		{
+			name: "pseudo from config",
+			args: args{
+				cfg: config{Pseudo: "en", PseudoLocale: "qps", PseudoPadding: 50},
+				f:   flags{inputs: []string{dirOk}, output: dirOk},
+			},
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk, Pseudo: "en", PseudoLocale: "qps", PseudoPadding: 50},
+		},
+		{
+			name: "bundles",
+			args: args{
+				cfg: config{Bundles: "web", BundleFormat: "flat", BundlePlaceholders: "double"},
+				f:   flags{inputs: []string{dirOk}, output: dirOk, bundles: "frontend", bundleFormat: "nested"},
+			},
+			want: generate.Options{
+				Inputs:             []string{dirOk},
+				Output:             dirOk,
+				Bundles:            "frontend",
+				BundleFormat:       generate.BundleNested,
+				BundlePlaceholders: generate.PlaceholdersDouble,
+			},
+		},
+		{
+			name: "bundles from config",
+			args: args{
+				cfg: config{Bundles: "web", BundleFormat: "flat", BundlePlaceholders: "double"},
+				f:   flags{inputs: []string{dirOk}, output: dirOk, bundlePlaceholders: "single"},
+			},
+			want: generate.Options{
+				Inputs:             []string{dirOk},
+				Output:             dirOk,
+				Bundles:            "web",
+				BundleFormat:       generate.BundleFlat,
+				BundlePlaceholders: generate.PlaceholdersSingle,
+			},
+		},
+		{
+			name: "typings",
+			args: args{
+				f: flags{inputs: []string{dirOk}, output: dirOk, bundles: "web", typings: true},
+			},
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk, Bundles: "web", Typings: true},
+		},
+		{
+			name: "typings from config",
+			args: args{
+				cfg: config{Bundles: "web", Typings: true},
+				f:   flags{inputs: []string{dirOk}, output: dirOk},
+			},
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk, Bundles: "web", Typings: true},
+		},
+		{
+			name: "android and apple",
+			args: args{
+				cfg: config{Android: "android", Apple: "ios"},
+				f:   flags{inputs: []string{dirOk}, output: dirOk, apple: "apple"},
+			},
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk, Android: "android", Apple: "apple"},
+		},
+		{
+			name: "arb",
+			args: args{
+				cfg: config{ARB: "l10n"},
+				f:   flags{inputs: []string{dirOk}, output: dirOk, arb: "flutter/l10n"},
+			},
+			want: generate.Options{Inputs: []string{dirOk}, Output: dirOk, ARB: "flutter/l10n"},
+		},
+		{
+			name: "invalid input",
+			args: args{
+				f: flags{inputs: []string{dirBlank}},
+			},
+			wantErr: errFlagInputNotSet,
+		},
+		{
+			name:    "no input",
+			args:    args{},
+			wantErr: errFlagInputNotSet,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := parseCSV(tt.args.value, tt.args.l)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("parseCSV() error = %v, wantErr %v", err, tt.wantErr)
+			got, err := parseFlags(tt.args.cfg, tt.args.f)
+			if (err != nil) != (tt.wantErr != nil) || err != tt.wantErr {
+				t.Errorf("parseFlags() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(tt.args.l, tt.want) && !tt.wantErr {
-				t.Errorf("parseCSV() got = %v, want %v", tt.args.l, tt.want)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFlags() got = %v, want %v", got, tt.want)
 			}
 		})
 	}